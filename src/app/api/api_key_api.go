@@ -0,0 +1,99 @@
+package api
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/result"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+// ApiKeyCreated 创建 API Key 响应，key 为明文，只返回这一次
+type ApiKeyCreated struct {
+	*auth.ApiKey
+	Key string `json:"key"` // API Key 明文
+}
+
+type ApiKeyApi struct{}
+
+func NewApiKeyApi() *ApiKeyApi {
+	return &ApiKeyApi{}
+}
+
+// Create godoc
+// @Summary      创建API Key
+// @Description  需要 apikey:admin 授权，返回的 key 明文只在创建时返回一次；授权范围不能超出调用方自身的权限，否则返回 Forbidden
+// @Tags         认证接口
+// @Accept       json
+// @Produce      json
+// @Param        X-API-Key header string true "API Key"
+// @Param        body body auth.ApiKeyCreate true "API Key信息"
+// @Success      200 {object} result.Response{data=ApiKeyCreated}
+// @Router       /auth/api_keys [POST]
+func (a *ApiKeyApi) Create(c *gin.Context) {
+	var req auth.ApiKeyCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+	key, plain, err := auth.CreateApiKey(c.Request.Context(), &req)
+	if errors.Is(err, auth.ErrInvalidScope) {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+	if errors.Is(err, auth.ErrScopeNotGranted) {
+		result.Error(c, result.Forbidden)
+		return
+	}
+	if err != nil {
+		result.Error(c, result.DBCreateFailed)
+		return
+	}
+	result.OK(c, &ApiKeyCreated{ApiKey: key, Key: plain})
+}
+
+// List godoc
+// @Summary      API Key列表
+// @Description  需要 apikey:admin 授权
+// @Tags         认证接口
+// @Produce      json
+// @Param        X-API-Key header string true "API Key"
+// @Param        owner query string false "所属方"
+// @Success      200 {object} result.Response{data=[]auth.ApiKey}
+// @Router       /auth/api_keys [GET]
+func (a *ApiKeyApi) List(c *gin.Context) {
+	list, err := auth.ListApiKeys(c.Request.Context(), c.Query("owner"))
+	if err != nil {
+		result.Error(c, result.DBQueryFailed)
+		return
+	}
+	result.OK(c, list)
+}
+
+// Revoke godoc
+// @Summary      吊销API Key
+// @Description  需要 apikey:admin 授权，吊销后立即失效
+// @Tags         认证接口
+// @Produce      json
+// @Param        X-API-Key header string true "API Key"
+// @Param        id path int true "API Key id"
+// @Success      200 {object} result.Response
+// @Router       /auth/api_keys/{id} [DELETE]
+func (a *ApiKeyApi) Revoke(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+	err = auth.RevokeApiKey(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		result.Error(c, result.DBNotExist)
+		return
+	}
+	if err != nil {
+		result.Error(c, result.DBUpdateFailed)
+		return
+	}
+	result.OK(c, nil)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"bossfi-backend/src/core/audit"
+	"bossfi-backend/src/core/query"
+	"bossfi-backend/src/core/result"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditApi struct{}
+
+func NewAuditApi() *AuditApi {
+	return &AuditApi{}
+}
+
+// Page godoc
+// @Summary      审计日志
+// @Description  需要 audit:read 授权；按操作者、实体及时间范围过滤 如 filter=actor:eq:0x123&filter=entity:eq:bossfi_demo&filter=create_time:range:2025-01-01,2025-02-01
+// @Tags         系统接口
+// @Produce      json
+// @Param        page      query int    false "页码(1-10000)" default(1)
+// @Param        page_size query int    false "每页条数(1-100)" default(10)
+// @Param        sort      query string false "排序 如 -id" default(-id)
+// @Param        filter    query []string false "过滤 支持 actor_type/actor/action/entity/entity_id/route/request_id/create_time" collectionFormat(multi)
+// @Success      200 {object} result.Response{data=result.Page[audit.Log]}
+// @Router       /sys/audit_logs [GET]
+func (a *AuditApi) Page(c *gin.Context) {
+	req, err := query.BindPage(c, audit.PageOptions)
+	if err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+
+	list, total, err := audit.Page(c.Request.Context(), req)
+	if err != nil {
+		result.Error(c, result.DBQueryFailed)
+		return
+	}
+
+	result.OK(c, result.NewPage(list, req.Page, req.Size, total))
+}
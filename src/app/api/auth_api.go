@@ -0,0 +1,164 @@
+package api
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/gin/middleware"
+	"bossfi-backend/src/core/result"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// PermissionsResp 调用方的有效权限
+type PermissionsResp struct {
+	Type        string   `json:"type"`        // 主体类型 api_key/wallet
+	Subject     string   `json:"subject"`     // 主体标识
+	Roles       []string `json:"roles"`       // 绑定的角色
+	Scopes      []string `json:"scopes"`      // API Key 授权范围
+	Permissions []string `json:"permissions"` // 有效权限
+}
+
+// WalletNonceReq 获取钱包登录消息请求
+type WalletNonceReq struct {
+	Address string `json:"address" binding:"required"` // 钱包地址
+}
+
+// WalletNonceResp 钱包登录消息，客户端使用钱包签名（personal_sign）后携带 nonce 登录
+type WalletNonceResp struct {
+	Nonce   string `json:"nonce"`
+	Message string `json:"message"`
+}
+
+// WalletLoginReq 钱包登录请求
+type WalletLoginReq struct {
+	Address   string `json:"address" binding:"required"`   // 钱包地址
+	Nonce     string `json:"nonce" binding:"required"`     // 获取登录消息时返回的 nonce
+	Signature string `json:"signature" binding:"required"` // 登录消息的签名 0x 开头的十六进制
+}
+
+// WalletLoginResp 钱包登录响应，后续请求通过 session_id 请求头携带 token
+type WalletLoginResp struct {
+	Token      string    `json:"token"`
+	ExpireTime time.Time `json:"expire_time"`
+}
+
+type AuthApi struct{}
+
+func NewAuthApi() *AuthApi {
+	return &AuthApi{}
+}
+
+// Permissions godoc
+// @Summary      当前调用方权限
+// @Description  返回调用方绑定的角色及有效权限（API Key 授权范围与角色权限的并集），未认证返回 Unauthorized
+// @Tags         认证接口
+// @Produce      json
+// @Param        X-API-Key header string false "API Key"
+// @Param        session_id header string false "钱包登录会话 token"
+// @Success      200 {object} result.Response{data=PermissionsResp}
+// @Router       /auth/permissions [GET]
+func (a *AuthApi) Permissions(c *gin.Context) {
+	p := auth.FromContext(c.Request.Context())
+	if p == nil {
+		result.Error(c, result.Unauthorized)
+		return
+	}
+	roles, err := auth.Roles(c.Request.Context(), p)
+	if err != nil {
+		result.Error(c, result.DBQueryFailed)
+		return
+	}
+	permissions, err := auth.Permissions(c.Request.Context(), p)
+	if err != nil {
+		result.Error(c, result.DBQueryFailed)
+		return
+	}
+	scopes := p.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	result.OK(c, &PermissionsResp{
+		Type:        p.Type,
+		Subject:     p.Subject,
+		Roles:       roles,
+		Scopes:      scopes,
+		Permissions: permissions,
+	})
+}
+
+// WalletNonce godoc
+// @Summary      获取钱包登录消息
+// @Description  返回 nonce 及待签名的登录消息，5 分钟内有效
+// @Tags         认证接口
+// @Accept       json
+// @Produce      json
+// @Param        body body WalletNonceReq true "钱包地址"
+// @Success      200 {object} result.Response{data=WalletNonceResp}
+// @Router       /auth/wallet/nonce [POST]
+func (a *AuthApi) WalletNonce(c *gin.Context) {
+	var req WalletNonceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+	nonce, message, err := auth.WalletNonce(c.Request.Context(), req.Address)
+	if errors.Is(err, auth.ErrInvalidAddress) {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+	if err != nil {
+		result.Error(c, result.RedisError)
+		return
+	}
+	result.OK(c, &WalletNonceResp{Nonce: nonce, Message: message})
+}
+
+// WalletLogin godoc
+// @Summary      钱包登录
+// @Description  校验登录消息的签名，成功返回会话 token（24 小时有效），后续请求通过 session_id 请求头携带；
+// @Description  签名错误或登录消息已过期、已使用返回 Unauthorized
+// @Tags         认证接口
+// @Accept       json
+// @Produce      json
+// @Param        body body WalletLoginReq true "钱包地址及签名"
+// @Success      200 {object} result.Response{data=WalletLoginResp}
+// @Router       /auth/wallet/login [POST]
+func (a *AuthApi) WalletLogin(c *gin.Context) {
+	var req WalletLoginReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+	token, err := auth.WalletLogin(c.Request.Context(), req.Address, req.Nonce, req.Signature)
+	switch {
+	case errors.Is(err, auth.ErrInvalidAddress):
+		result.Error(c, result.InvalidParameter)
+	case errors.Is(err, auth.ErrInvalidSignature):
+		result.Error(c, result.Unauthorized)
+	case err != nil:
+		result.Error(c, result.RedisError)
+	default:
+		result.OK(c, &WalletLoginResp{Token: token, ExpireTime: time.Now().Add(auth.SessionTTL)})
+	}
+}
+
+// Logout godoc
+// @Summary      退出登录
+// @Description  删除 session_id 请求头对应的钱包登录会话
+// @Tags         认证接口
+// @Produce      json
+// @Param        session_id header string true "会话 token"
+// @Success      200 {object} result.Response
+// @Router       /auth/logout [POST]
+func (a *AuthApi) Logout(c *gin.Context) {
+	token := c.GetHeader(middleware.SessionHeader)
+	if token == "" {
+		result.Error(c, result.Unauthorized)
+		return
+	}
+	if err := auth.Logout(c.Request.Context(), token); err != nil {
+		result.Error(c, result.RedisError)
+		return
+	}
+	result.OK(c, nil)
+}
@@ -3,11 +3,53 @@ package api
 import (
 	"bossfi-backend/src/app/model"
 	"bossfi-backend/src/app/service"
+	"bossfi-backend/src/core/patch"
+	"bossfi-backend/src/core/query"
 	"bossfi-backend/src/core/result"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"io"
 	"strconv"
 )
 
+// demoPageOptions 分页查询可排序/过滤字段白名单
+var demoPageOptions = query.Options{
+	DefaultSort: "-id",
+	SortFields: map[string]string{
+		"id":          "id",
+		"create_time": "create_time",
+		"modify_time": "modify_time",
+	},
+	FilterFields: map[string]string{
+		"id":          "id",
+		"address":     "address",
+		"create_time": "create_time",
+		"modify_time": "modify_time",
+	},
+}
+
+// demoDeletedPageOptions 已删除记录分页查询选项，额外支持按删除时间、操作者排序/过滤
+var demoDeletedPageOptions = query.Options{
+	DefaultSort: "-deleted_at",
+	SortFields: map[string]string{
+		"id":         "id",
+		"deleted_at": "deleted_at",
+	},
+	FilterFields: map[string]string{
+		"id":         "id",
+		"address":    "address",
+		"deleted_at": "deleted_at",
+		"deleted_by": "deleted_by",
+	},
+}
+
+// demoCursorOptions 游标分页查询选项，demo 为逻辑删除表，估算不生效，默认精确统计
+var demoCursorOptions = query.CursorOptions{
+	Options:      demoPageOptions,
+	DefaultCount: query.CountExact,
+}
+
 type DemoApi struct {
 	svc *service.DemoService
 }
@@ -27,22 +69,24 @@ func NewDemoApi() *DemoApi {
 // @Success      200 {object} map[string]string
 // @Router       /demo/create [POST]
 func (s *DemoApi) Create(c *gin.Context) {
-	var req model.Demo
+	var req model.DemoUpdate
 	if err := c.ShouldBindJSON(&req); err != nil {
 		result.Error(c, result.InvalidParameter)
 		return
 	}
-	if err := s.svc.Create(&req); err != nil {
+	// 只使用可修改的字段，id、deleted、version 等由服务端生成
+	demo := &model.Demo{Address: req.Address, Logs: req.Logs}
+	if err := s.svc.Create(c.Request.Context(), demo); err != nil {
 		result.Error(c, result.DBCreateFailed)
 		return
 	}
-	result.OK(c, req)
+	result.OK(c, demo)
 }
 
 // GetById 查询数据
 func (s *DemoApi) GetById(c *gin.Context) {
 	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
-	demo, err := s.svc.GetById(id)
+	demo, err := s.svc.GetById(c.Request.Context(), id)
 	if err != nil {
 		result.Error(c, result.DBNotExist)
 		return
@@ -50,35 +94,170 @@ func (s *DemoApi) GetById(c *gin.Context) {
 	result.OK(c, demo)
 }
 
-// Update 更新数据
+// Update godoc
+// @Summary      更新数据
+// @Description  整体更新可修改的字段（address、logs），其他字段忽略；version 为读取时的版本号，必须携带，
+// @Description  缺少时返回 VersionRequired(HTTP 428)，与当前版本不一致时返回 VersionConflict(HTTP 409)
+// @Tags         示例接口
+// @Accept       json
+// @Produce      json
+// @Param        id   path int              true "id"
+// @Param        body body model.DemoUpdate true "可修改的字段"
+// @Success      200 {object} result.Response{data=model.Demo}
+// @Router       /demo/{id} [PUT]
 func (s *DemoApi) Update(c *gin.Context) {
 	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
-	var req model.Demo
+	var req model.DemoUpdate
 	if err := c.ShouldBindJSON(&req); err != nil {
 		result.Error(c, result.InvalidParameter)
 		return
 	}
-	req.ID = id
-	if err := s.svc.Update(&req); err != nil {
-		result.Error(c, result.DBUpdateFailed)
+	// 整体更新会覆盖全部字段，必须基于读取到的版本，避免覆盖他人的修改
+	if req.Version == 0 {
+		result.Error(c, result.VersionRequired)
+		return
+	}
+	demo, err := s.svc.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		updateError(c, err)
+		return
+	}
+	result.OK(c, demo)
+}
+
+// Patch godoc
+// @Summary      部分更新数据
+// @Description  JSON Merge Patch(RFC 7386)，只能包含 address、logs、version，包含其他字段返回 FieldNotWritable(HTTP 422)；
+// @Description  值为 null 的字段清空；指定 version 时与当前版本不一致返回 VersionConflict(HTTP 409)；
+// @Description  Content-Type 必须为 application/merge-patch+json，否则返回 UnsupportedMediaType(HTTP 415)
+// @Tags         示例接口
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Param        id   path int              true "id"
+// @Param        body body model.DemoUpdate true "merge patch，只需包含要修改的字段"
+// @Success      200 {object} result.Response{data=model.Demo}
+// @Router       /demo/{id} [PATCH]
+func (s *DemoApi) Patch(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if c.ContentType() != patch.MergePatchContentType {
+		result.Error(c, result.UnsupportedMediaType)
 		return
 	}
-	result.OK(c, req)
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+	demo, err := s.svc.Patch(c.Request.Context(), id, body)
+	if err != nil {
+		updateError(c, err)
+		return
+	}
+	result.OK(c, demo)
+}
+
+// updateError 更新失败的业务状态码
+func updateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		result.Error(c, result.DBNotExist)
+	case errors.Is(err, model.ErrVersionConflict):
+		result.Error(c, result.VersionConflict)
+	case errors.Is(err, patch.ErrNotWritable):
+		result.Error(c, result.FieldNotWritable)
+	case errors.Is(err, patch.ErrInvalid):
+		result.Error(c, result.InvalidParameter)
+	default:
+		result.Error(c, result.DBUpdateFailed)
+	}
 }
 
 // Delete 删除数据
 func (s *DemoApi) Delete(c *gin.Context) {
 	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err := s.svc.Delete(id); err != nil {
+	if err := s.svc.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Error(c, result.DBNotExist)
+			return
+		}
+		result.Error(c, result.DBDeleteFailed)
+		return
+	}
+	result.OK(c, nil)
+}
+
+// Restore godoc
+// @Summary      恢复已删除数据
+// @Tags         示例接口
+// @Produce      json
+// @Param        id path int true "id"
+// @Success      200 {object} result.Response{data=model.Demo}
+// @Router       /demo/{id}/restore [POST]
+func (s *DemoApi) Restore(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	demo, err := s.svc.Restore(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Error(c, result.DBNotExist)
+			return
+		}
+		result.Error(c, result.DBUpdateFailed)
+		return
+	}
+	result.OK(c, demo)
+}
+
+// Purge godoc
+// @Summary      永久删除数据
+// @Description  仅限管理员，只能永久删除已删除(deleted=true)的记录，未删除的记录返回 DBNotExist
+// @Tags         示例接口
+// @Produce      json
+// @Param        id path int true "id"
+// @Success      200 {object} result.Response
+// @Router       /demo/{id}/purge [DELETE]
+func (s *DemoApi) Purge(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err := s.svc.Purge(c.Request.Context(), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Error(c, result.DBNotExist)
+			return
+		}
 		result.Error(c, result.DBDeleteFailed)
 		return
 	}
 	result.OK(c, nil)
 }
 
+// Deleted godoc
+// @Summary      分页查询已删除数据
+// @Description  默认按删除时间倒序；filter 支持 deleted_at:range:开始,结束、deleted_by:eq:wallet:0x123
+// @Tags         示例接口
+// @Produce      json
+// @Param        page      query int    false "页码(1-10000)" default(1)
+// @Param        page_size query int    false "每页条数(1-100)" default(10)
+// @Param        sort      query string false "排序 如 -deleted_at"
+// @Param        filter    query []string false "过滤 如 deleted_by:eq:system" collectionFormat(multi)
+// @Success      200 {object} result.Response{data=result.Page[model.Demo]}
+// @Router       /demo/deleted [GET]
+func (s *DemoApi) Deleted(c *gin.Context) {
+	req, err := query.BindPage(c, demoDeletedPageOptions)
+	if err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+
+	list, total, err := s.svc.Deleted(c.Request.Context(), req)
+	if err != nil {
+		result.Error(c, result.DBQueryFailed)
+		return
+	}
+
+	result.OK(c, result.NewPage(list, req.Page, req.Size, total))
+}
+
 // List 查询列表
 func (s *DemoApi) List(c *gin.Context) {
-	list, err := s.svc.List()
+	list, err := s.svc.List(c.Request.Context())
 	if err != nil {
 		result.Error(c, result.DBQueryFailed)
 		return
@@ -86,23 +265,57 @@ func (s *DemoApi) List(c *gin.Context) {
 	result.OK(c, list)
 }
 
-// Page 分页查询数据
+// Page godoc
+// @Summary      分页查询数据
+// @Description  sort 为逗号分隔的排序字段，"-" 前缀表示倒序；filter 格式为 field:op:value，op 支持 eq/in/like/range
+// @Tags         示例接口
+// @Produce      json
+// @Param        page      query int    false "页码(1-10000)" default(1)
+// @Param        page_size query int    false "每页条数(1-100)" default(10)
+// @Param        sort      query string false "排序 如 -create_time,id"
+// @Param        filter    query []string false "过滤 如 address:eq:0x123、id:range:1,100" collectionFormat(multi)
+// @Success      200 {object} result.Response{data=result.Page[model.Demo]}
+// @Router       /demo/page [GET]
 func (s *DemoApi) Page(c *gin.Context) {
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("page_size", "10")
+	req, err := query.BindPage(c, demoPageOptions)
+	if err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+
+	list, total, err := s.svc.Page(c.Request.Context(), req)
+	if err != nil {
+		result.Error(c, result.DBQueryFailed)
+		return
+	}
 
-	page, _ := strconv.Atoi(pageStr)
-	pageSize, _ := strconv.Atoi(pageSizeStr)
+	result.OK(c, result.NewPage(list, req.Page, req.Size, total))
+}
+
+// Cursor godoc
+// @Summary      游标分页查询数据
+// @Description  适用于大表的键集分页，使用响应中的 next/prev 作为 cursor 参数翻页；携带 cursor 时 sort 参数被忽略
+// @Tags         示例接口
+// @Produce      json
+// @Param        page_size query int    false "每页条数(1-100)" default(10)
+// @Param        sort      query string false "排序字段(仅支持一个) 如 -create_time"
+// @Param        cursor    query string false "游标"
+// @Param        count     query string false "总数统计模式 exact|approx|none，approx 有过滤条件或为逻辑删除表时按 exact 统计" default(exact)
+// @Param        filter    query []string false "过滤 如 address:eq:0x123" collectionFormat(multi)
+// @Success      200 {object} result.Response{data=result.CursorPage[model.Demo]}
+// @Router       /demo/cursor [GET]
+func (s *DemoApi) Cursor(c *gin.Context) {
+	req, err := query.BindCursor(c, demoCursorOptions)
+	if err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
 
-	list, total, err := s.svc.Page(page, pageSize)
+	list, meta, total, err := s.svc.Cursor(c.Request.Context(), req)
 	if err != nil {
 		result.Error(c, result.DBQueryFailed)
 		return
 	}
 
-	// 返回分页结果
-	result.OK(c, gin.H{
-		"list":  list,
-		"total": total,
-	})
+	result.OK(c, result.NewCursorPage(list, req.Size, meta.Next, meta.Prev, meta.HasMore, total))
 }
@@ -1,43 +1,117 @@
 package api
 
 import (
+	"bossfi-backend/src/app/service"
 	"bossfi-backend/src/common/chain"
-	"bossfi-backend/src/core/chainclient/domain"
-	"bossfi-backend/src/core/ctx"
 	"bossfi-backend/src/core/result"
-	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gin-gonic/gin"
-	"math/big"
 	"strconv"
 )
 
-type EvmApi struct{}
+const (
+	// cacheFinal 已终局的数据不再变化，客户端可长期缓存
+	cacheFinal = "public, max-age=86400, immutable"
+	// cacheUnfinal 未终局的数据可能因重组变化，每次使用 ETag 校验
+	cacheUnfinal = "no-cache"
+)
+
+type EvmApi struct {
+	svc *service.EvmService
+}
 
 func NewEvmApi() *EvmApi {
-	return &EvmApi{}
+	return &EvmApi{
+		svc: service.NewEvmService(),
+	}
 }
 
 // GetBlockByNum curl "http://localhost:8000/api/v1/evm/get_block_by_num/8615565"
 func (e *EvmApi) GetBlockByNum(c *gin.Context) {
-	blockNumString := c.Params.ByName("block_num")
-	if blockNumString == "" {
+	chainId, ok := chainIdParam(c)
+	if !ok {
 		result.Error(c, result.InvalidParameter)
 		return
 	}
-	blockNum, err := strconv.ParseInt(blockNumString, 10, 64)
+	blockNum, err := strconv.ParseUint(c.Params.ByName("block_num"), 10, 64)
+	if err != nil {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+
+	block, err := e.svc.GetBlockByNum(c.Request.Context(), chainId, blockNum)
 	if err != nil {
+		evmError(c, err)
+		return
+	}
+
+	e.cacheControl(c, chainId, block.Number)
+	result.OK(c, block)
+}
+
+// GetTransaction curl "http://localhost:8000/api/v1/evm/get_tx_by_hash/0x..."
+func (e *EvmApi) GetTransaction(c *gin.Context) {
+	chainId, ok := chainIdParam(c)
+	hash := c.Params.ByName("hash")
+	if !ok || !isHash(hash) {
 		result.Error(c, result.InvalidParameter)
 		return
 	}
 
-	blockNewHeader := big.NewInt(blockNum)
+	tx, err := e.svc.GetTransaction(c.Request.Context(), chainId, hash)
+	if err != nil {
+		evmError(c, err)
+		return
+	}
+
+	result.OK(c, tx)
+}
+
+// GetReceipt curl "http://localhost:8000/api/v1/evm/get_receipt/0x..."
+func (e *EvmApi) GetReceipt(c *gin.Context) {
+	chainId, ok := chainIdParam(c)
+	hash := c.Params.ByName("hash")
+	if !ok || !isHash(hash) {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
 
-	client := ctx.GetEvmClient(chain.SepoliaChainID)
-	block, err := client.BlockByNumber(context.Background(), blockNewHeader)
+	receipt, err := e.svc.GetReceipt(c.Request.Context(), chainId, hash)
 	if err != nil {
-		result.Error(c, result.EthereumError)
+		evmError(c, err)
+		return
+	}
+
+	e.cacheControl(c, chainId, receipt.BlockNumber)
+	result.OK(c, receipt)
+}
+
+// cacheControl 按区块是否终局设置响应缓存策略，响应带 ETag，If-None-Match 一致时返回 304
+func (e *EvmApi) cacheControl(c *gin.Context, chainId int, num uint64) {
+	if e.svc.IsFinal(c.Request.Context(), chainId, num) {
+		result.CacheControl(c, cacheFinal)
 		return
 	}
+	result.CacheControl(c, cacheUnfinal)
+}
 
-	result.OK(c, domain.ToBlock(block))
+// chainIdParam 链ID，查询参数 chain_id，默认 Sepolia
+func chainIdParam(c *gin.Context) (int, bool) {
+	chainId, err := strconv.Atoi(c.DefaultQuery("chain_id", strconv.Itoa(chain.SepoliaChainID)))
+	return chainId, err == nil
+}
+
+func isHash(s string) bool {
+	b, err := hexutil.Decode(s)
+	return err == nil && len(b) == common.HashLength
+}
+
+func evmError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrUnsupportedChain) {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+	result.Error(c, result.EthereumError)
 }
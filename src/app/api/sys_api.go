@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bossfi-backend/src/core/mq"
+	"bossfi-backend/src/core/result"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// maxDeadLetters 单次查询死信消息最大条数
+const maxDeadLetters = 100
+
+// DeadLetter 死信消息
+type DeadLetter struct {
+	ID      string `json:"id"`      // 原消息ID
+	Topic   string `json:"topic"`   // topic
+	Group   string `json:"group"`   // 处理失败的消费组
+	Attempt int    `json:"attempt"` // 投递次数
+	Error   string `json:"error"`   // 最后一次处理失败的原因
+	Payload string `json:"payload"` // 消息内容
+}
+
+type SysApi struct{}
+
+func NewSysApi() *SysApi {
+	return &SysApi{}
+}
+
+// Codes godoc
+// @Summary      业务状态码表
+// @Description  导出全部业务状态码及其HTTP状态码和多语言消息，format=md 时返回Markdown表格
+// @Tags         系统接口
+// @Produce      json
+// @Param        format query string false "导出格式 json|md" default(json)
+// @Success      200 {object} result.Response{data=[]result.CodeDoc}
+// @Router       /sys/codes [GET]
+func (s *SysApi) Codes(c *gin.Context) {
+	if c.Query("format") == "md" {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(result.ExportMarkdown()))
+		return
+	}
+	result.OK(c, result.ExportDocs())
+}
+
+// DeadLetters godoc
+// @Summary      死信消息
+// @Description  查询 topic 最近处理失败进入死信队列的消息
+// @Tags         系统接口
+// @Produce      json
+// @Param        topic query string true "topic"
+// @Param        count query int false "条数" default(20)
+// @Success      200 {object} result.Response{data=[]DeadLetter}
+// @Router       /sys/mq/dead_letters [GET]
+func (s *SysApi) DeadLetters(c *gin.Context) {
+	topic := c.Query("topic")
+	count, err := strconv.Atoi(c.DefaultQuery("count", "20"))
+	if topic == "" || err != nil || count <= 0 || count > maxDeadLetters {
+		result.Error(c, result.InvalidParameter)
+		return
+	}
+
+	msgs, err := mq.Default.DeadLetters(c.Request.Context(), topic, count)
+	if err != nil {
+		result.Error(c, result.MQError)
+		return
+	}
+	list := make([]*DeadLetter, 0, len(msgs))
+	for _, msg := range msgs {
+		list = append(list, &DeadLetter{
+			ID:      msg.ID,
+			Topic:   msg.Topic,
+			Group:   msg.Group,
+			Attempt: msg.Attempt,
+			Error:   msg.Error,
+			Payload: string(msg.Payload),
+		})
+	}
+	result.OK(c, list)
+}
@@ -0,0 +1,86 @@
+package job
+
+import (
+	"bossfi-backend/src/app/model"
+	"bossfi-backend/src/app/service"
+	"bossfi-backend/src/core/audit"
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/lock"
+	"bossfi-backend/src/core/log"
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	defaultRetentionDays      = 30
+	defaultRetentionInterval  = 3600
+	defaultRetentionBatchSize = 100
+)
+
+// purgeFunc 永久删除删除时间早于 before 的记录，每次最多 limit 条，返回删除条数
+type purgeFunc func(ctx context.Context, before time.Time, limit int) (int64, error)
+
+// retentionTables 参与定时清理的逻辑删除表，新增逻辑删除表时在此登记
+var retentionTables = map[string]purgeFunc{
+	model.Demo{}.TableName(): service.NewDemoService().PurgeExpired,
+}
+
+// StartRetention 启动逻辑删除数据清理任务：按 [retention] interval 定时永久删除删除时间超过 days 天的记录，
+// 多副本时通过主节点选举只有一个实例执行
+func StartRetention(ctx context.Context) {
+	conf := config.Conf.Retention
+	if !conf.Enable {
+		return
+	}
+	days, interval, batch := conf.Days, conf.Interval, conf.BatchSize
+	if days <= 0 {
+		days = defaultRetentionDays
+	}
+	if interval <= 0 {
+		interval = defaultRetentionInterval
+	}
+	if batch <= 0 {
+		batch = defaultRetentionBatchSize
+	}
+	// 开启审计时单条删除语句不能超过审计的 max_rows
+	if max := audit.MaxRows(); max > 0 && batch > max {
+		batch = max
+	}
+	retention := time.Duration(days) * 24 * time.Hour
+
+	go lock.Default.NewElection("retention", time.Minute).Run(ctx, func(ctx context.Context, fence int64) error {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			purgeExpired(ctx, time.Now().Add(-retention), batch)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// purgeExpired 逐表分批永久删除，某批不足 batch 条时该表清理完成
+func purgeExpired(ctx context.Context, before time.Time, batch int) {
+	for table, purge := range retentionTables {
+		var total int64
+		for ctx.Err() == nil {
+			n, err := purge(ctx, before, batch)
+			if err != nil {
+				log.Logger.Error("purge expired records error", zap.String("table", table), zap.Error(err))
+				break
+			}
+			total += n
+			if n < int64(batch) {
+				break
+			}
+		}
+		if total > 0 {
+			log.Logger.Info("purged expired records", zap.String("table", table),
+				zap.Int64("count", total), zap.Time("before", before))
+		}
+	}
+}
@@ -1,18 +1,29 @@
 package model
 
 import (
-	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/audit"
 	"time"
 )
 
+func init() {
+	audit.Track(Demo{}.TableName())
+}
+
+// DemoModel 示例表，通用CRUD由 Repository 提供
 type DemoModel struct {
+	Repository[Demo]
 }
 
+var _ Model[Demo] = (*DemoModel)(nil)
+
 type Demo struct {
 	ID         int64                  `json:"id" gorm:"column:id;primaryKey"`
 	Address    string                 `json:"address" gorm:"column:address"`
 	Logs       map[string]interface{} `json:"logs" gorm:"column:logs;type:jsonb;serializer:json"`
 	Deleted    bool                   `json:"deleted" gorm:"column:deleted;default:false"`
+	DeletedAt  *time.Time             `json:"deleted_at" gorm:"column:deleted_at"`
+	DeletedBy  string                 `json:"deleted_by" gorm:"column:deleted_by"`
+	Version    int64                  `json:"version" gorm:"column:version;default:1"`
 	CreateTime time.Time              `json:"create_time" gorm:"column:create_time;autoCreateTime"`
 	ModifyTime time.Time              `json:"modify_time" gorm:"column:modify_time;autoUpdateTime"`
 }
@@ -21,59 +32,25 @@ func (Demo) TableName() string {
 	return "bossfi_demo"
 }
 
-// Create 创建记录
-func (m *DemoModel) Create(demo *Demo) error {
-	return db.DB.Create(demo).Error
-}
-
-// GetById 查询单条记录
-func (m *DemoModel) GetById(id int64) (*Demo, error) {
-	var demo Demo
-	err := db.DB.Scopes(NotDeleted).Where("id = ?", id).First(&demo).Error
-	if err != nil {
-		return nil, err
-	}
-	return &demo, nil
-}
-
-// UpdateById 更新记录
-func (m *DemoModel) UpdateById(demo *Demo) error {
-	return db.DB.Save(demo).Error
+// DemoUpdate 客户端可修改的字段，id、deleted、create_time 等由服务端维护
+//
+// 作为 JSON Merge Patch 的目标时只允许修改这里序列化出的字段，不要使用 omitempty
+type DemoUpdate struct {
+	Address string                 `json:"address"`
+	Logs    map[string]interface{} `json:"logs"`
+	Version int64                  `json:"version"` // 客户端读取时的版本号，用于乐观锁，0 表示不校验
 }
 
-// DeleteById 逻辑删除记录
-func (m *DemoModel) DeleteById(id int64) error {
-	return db.DB.Scopes(NotDeleted).Model(&Demo{}).
-		Where("id = ?", id).
-		Update("deleted", true).Error
+// NewDemoUpdate 由当前记录生成可修改字段，版本号为 0
+func NewDemoUpdate(d *Demo) *DemoUpdate {
+	return &DemoUpdate{Address: d.Address, Logs: d.Logs}
 }
 
-// List 查询所有未删除记录
-func (m *DemoModel) List() ([]*Demo, error) {
-	var list []*Demo
-	err := db.DB.Scopes(NotDeleted).Find(&list).Error
-	if err != nil {
-		return nil, err
+// ApplyTo 写入可修改的字段，版本号不为 0 时作为乐观锁的期望版本
+func (u *DemoUpdate) ApplyTo(d *Demo) {
+	d.Address = u.Address
+	d.Logs = u.Logs
+	if u.Version != 0 {
+		d.Version = u.Version
 	}
-	return list, nil
-}
-
-// Page 查询分页数据
-func (m *DemoModel) Page(page, size int) ([]*Demo, int64, error) {
-	var list []*Demo
-	var total int64
-
-	res := db.DB.Scopes(NotDeleted).Model(&Demo{})
-
-	// 获取总数
-	if err := res.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// 分页查询
-	if err := res.Offset((page - 1) * size).Limit(size).Find(&list).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return list, total, nil
 }
@@ -9,6 +9,27 @@ type Model[T any] interface {
 	DeleteById(id int64) error
 }
 
+// includeDeletedKey 查询包含已逻辑删除记录的标记
+const includeDeletedKey = "bossfi:include_deleted"
+
 func NotDeleted(db *gorm.DB) *gorm.DB {
 	return db.Where("deleted = false")
 }
+
+// IncludeDeleted 仓储查询包含已逻辑删除的记录 如 repo.List(model.IncludeDeleted)
+func IncludeDeleted(db *gorm.DB) *gorm.DB {
+	return db.Set(includeDeletedKey, true)
+}
+
+// OnlyDeleted 仓储查询只返回已逻辑删除的记录
+func OnlyDeleted(db *gorm.DB) *gorm.DB {
+	return IncludeDeleted(db).Where("deleted = true")
+}
+
+// softDeleteFilter 逻辑删除表默认过滤已删除记录，查询条件包含 IncludeDeleted 时不过滤
+func softDeleteFilter(db *gorm.DB) *gorm.DB {
+	if v, ok := db.Get(includeDeletedKey); ok && v == true {
+		return db
+	}
+	return NotDeleted(db)
+}
@@ -0,0 +1,399 @@
+package model
+
+import (
+	"bossfi-backend/src/core/audit"
+	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/query"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"time"
+)
+
+// Scope GORM 查询条件
+type Scope = func(*gorm.DB) *gorm.DB
+
+// ErrVersionConflict 乐观锁版本不一致，记录已被修改
+var ErrVersionConflict = errors.New("version conflict")
+
+// ErrNotSoftDelete 表不是逻辑删除表
+var ErrNotSoftDelete = errors.New("table does not support soft delete")
+
+// protectedColumns UpdateById 不更新的列，创建时间及逻辑删除标记由专门的方法维护
+var protectedColumns = []string{"create_time", "deleted", "deleted_at", "deleted_by"}
+
+// Repository 通用仓储，实现 Model[T]，业务表嵌入即可获得基础CRUD
+//
+// 表中存在 deleted 列时自动按逻辑删除处理：查询过滤已删除记录（IncludeDeleted 时不过滤），删除改为更新 deleted = true，
+// 存在 deleted_at/deleted_by 列时同时记录删除时间及操作者；存在 version 列时更新使用乐观锁
+type Repository[T any] struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建使用指定连接的仓储，db 为 nil 时使用主数据源
+func NewRepository[T any](db *gorm.DB) *Repository[T] {
+	return &Repository[T]{db: db}
+}
+
+// WithDB 返回使用指定连接（如事务）的仓储副本
+func (r *Repository[T]) WithDB(db *gorm.DB) *Repository[T] {
+	return &Repository[T]{db: db}
+}
+
+// WithContext 返回绑定 context 的仓储副本，context 中存在事务（db.WithTx）时自动加入该事务
+func (r *Repository[T]) WithContext(ctx context.Context) *Repository[T] {
+	if tx, ok := db.TxFromContext(ctx); ok {
+		return &Repository[T]{db: tx.WithContext(ctx)}
+	}
+	return &Repository[T]{db: r.DB().WithContext(ctx)}
+}
+
+// DB 仓储使用的数据库连接
+func (r *Repository[T]) DB() *gorm.DB {
+	if r.db != nil {
+		return r.db
+	}
+	return db.DB
+}
+
+// schema 解析模型结构
+func (r *Repository[T]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: r.DB()}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// SoftDelete 是否为逻辑删除表
+func (r *Repository[T]) SoftDelete() bool {
+	s, err := r.schema()
+	return err == nil && s.LookUpField("deleted") != nil
+}
+
+// Versioned 是否使用乐观锁（存在 version 列）
+func (r *Repository[T]) Versioned() bool {
+	s, err := r.schema()
+	return err == nil && s.LookUpField("version") != nil
+}
+
+// TableName 表名
+func (r *Repository[T]) TableName() string {
+	s, err := r.schema()
+	if err != nil {
+		return ""
+	}
+	return s.Table
+}
+
+// query 查询基础条件，逻辑删除表过滤已删除记录（scopes 包含 IncludeDeleted 时不过滤）
+func (r *Repository[T]) query(scopes ...Scope) *gorm.DB {
+	tx := r.DB().Model(new(T)).Scopes(scopes...)
+	if r.SoftDelete() {
+		tx = tx.Scopes(softDeleteFilter)
+	}
+	return tx
+}
+
+// GetById 查询单条记录
+func (r *Repository[T]) GetById(id int64) (*T, error) {
+	var v T
+	err := r.query().Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).First(&v).Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Create 创建记录
+func (r *Repository[T]) Create(v *T) error {
+	return r.DB().Create(v).Error
+}
+
+// UpdateById 按主键更新记录的全部字段（创建时间、逻辑删除标记除外），已逻辑删除或不存在的记录返回 gorm.ErrRecordNotFound
+//
+// 存在 version 列时使用乐观锁：v 的版本号与数据库一致时才更新并将版本号加 1，否则返回 ErrVersionConflict；
+// 版本号为 0 时以数据库当前版本为准
+func (r *Repository[T]) UpdateById(v *T) (err error) {
+	s, err := r.schema()
+	if err != nil {
+		return err
+	}
+	ctx := r.DB().Statement.Context
+	rv := reflect.ValueOf(v).Elem()
+	pk := s.PrioritizedPrimaryField
+	id, zero := pk.ValueOf(ctx, rv)
+	if zero {
+		return gorm.ErrRecordNotFound
+	}
+	byId := func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.PrimaryColumn, Value: id})
+	}
+
+	tx := r.query(byId)
+	version := s.LookUpField("version")
+	if version != nil {
+		var expected int64
+		if expected, err = r.version(v, version, byId); err != nil {
+			return err
+		}
+		tx = tx.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: version.DBName}, Value: expected})
+		if err = version.Set(ctx, rv, expected+1); err != nil {
+			return err
+		}
+		// 更新失败时恢复调用方的版本号
+		defer func() {
+			if err != nil {
+				_ = version.Set(ctx, rv, expected)
+			}
+		}()
+	}
+
+	res := tx.Select("*").Omit(append([]string{pk.DBName}, protectedColumns...)...).Updates(v)
+	if err = res.Error; err != nil {
+		return err
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+	// 未更新任何行：记录不存在，或版本已变化
+	exists, err := r.Exists(byId)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !exists:
+		err = gorm.ErrRecordNotFound
+	case version != nil:
+		err = ErrVersionConflict
+	}
+	return err
+}
+
+// version v 的版本号，为 0 时查询数据库当前版本
+func (r *Repository[T]) version(v *T, field *schema.Field, byId Scope) (int64, error) {
+	value, zero := field.ValueOf(r.DB().Statement.Context, reflect.ValueOf(v).Elem())
+	if !zero {
+		return reflect.ValueOf(value).Convert(reflect.TypeOf(int64(0))).Int(), nil
+	}
+	var versions []int64
+	if err := r.query(byId).Pluck(field.DBName, &versions).Error; err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return versions[0], nil
+}
+
+// DeleteById 删除记录，逻辑删除表仅标记 deleted 并记录删除时间及操作者，记录不存在或已删除时返回 gorm.ErrRecordNotFound
+func (r *Repository[T]) DeleteById(id int64) error {
+	var res *gorm.DB
+	if r.SoftDelete() {
+		res = r.query().
+			Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).
+			Updates(r.softDeleteValues(true))
+	} else {
+		res = r.DB().Delete(new(T), id)
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RestoreById 恢复已逻辑删除的记录，记录不存在或未删除时返回 gorm.ErrRecordNotFound
+func (r *Repository[T]) RestoreById(id int64) error {
+	if !r.SoftDelete() {
+		return ErrNotSoftDelete
+	}
+	res := r.query(OnlyDeleted).
+		Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).
+		Updates(r.softDeleteValues(false))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeById 永久删除已逻辑删除的记录，记录不存在或未删除时返回 gorm.ErrRecordNotFound
+func (r *Repository[T]) PurgeById(id int64) error {
+	if !r.SoftDelete() {
+		return ErrNotSoftDelete
+	}
+	res := r.query(OnlyDeleted).
+		Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).
+		Delete(new(T))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeDeleted 永久删除删除时间早于 before 的已逻辑删除记录，每次最多 limit 条，返回删除条数；表需有 deleted_at 列
+func (r *Repository[T]) PurgeDeleted(before time.Time, limit int) (int64, error) {
+	s, err := r.schema()
+	if err != nil {
+		return 0, err
+	}
+	if s.LookUpField("deleted") == nil || s.LookUpField("deleted_at") == nil {
+		return 0, ErrNotSoftDelete
+	}
+	pk := s.PrioritizedPrimaryField.DBName
+
+	// PostgreSQL 的 DELETE 不支持 LIMIT，先按主键查出本批记录
+	var ids []interface{}
+	err = r.query(OnlyDeleted).
+		Where(clause.Lt{Column: clause.Column{Name: "deleted_at"}, Value: before}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: pk}}).
+		Limit(limit).
+		Pluck(pk, &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	res := r.query(OnlyDeleted).
+		Where(clause.IN{Column: clause.PrimaryColumn, Values: ids}).
+		Delete(new(T))
+	return res.RowsAffected, res.Error
+}
+
+// softDeleteValues 逻辑删除/恢复时更新的列，存在时同时更新删除时间、操作者及版本号
+func (r *Repository[T]) softDeleteValues(deleted bool) map[string]interface{} {
+	values := map[string]interface{}{"deleted": deleted}
+	s, err := r.schema()
+	if err != nil {
+		return values
+	}
+	if s.LookUpField("deleted_at") != nil {
+		values["deleted_at"] = nil
+		if deleted {
+			values["deleted_at"] = time.Now()
+		}
+	}
+	if s.LookUpField("deleted_by") != nil {
+		values["deleted_by"] = ""
+		if deleted {
+			values["deleted_by"] = deletedBy(r.DB().Statement.Context)
+		}
+	}
+	if s.LookUpField("version") != nil {
+		values["version"] = gorm.Expr("version + 1")
+	}
+	return values
+}
+
+// deletedBy 删除操作者 如 wallet:0x123、api_key:1、system
+func deletedBy(ctx context.Context) string {
+	actorType, actor := audit.Actor(ctx)
+	if actor == "" {
+		return actorType
+	}
+	return actorType + ":" + actor
+}
+
+// List 查询所有记录
+func (r *Repository[T]) List(scopes ...Scope) ([]*T, error) {
+	var list []*T
+	if err := r.query(scopes...).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Count 统计记录数
+func (r *Repository[T]) Count(scopes ...Scope) (int64, error) {
+	var total int64
+	err := r.query(scopes...).Count(&total).Error
+	return total, err
+}
+
+// Exists 是否存在满足条件的记录
+func (r *Repository[T]) Exists(scopes ...Scope) (bool, error) {
+	var ones []int
+	tx := r.query(scopes...).Select("1").Limit(1).Find(&ones)
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return len(ones) > 0, nil
+}
+
+// Page 查询分页数据
+func (r *Repository[T]) Page(req *query.PageReq, scopes ...Scope) ([]*T, int64, error) {
+	var list []*T
+	var total int64
+
+	res := r.query(scopes...).Scopes(req.Filter)
+
+	// 获取总数
+	if err := res.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := res.Scopes(req.Sort, req.Paginate).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return list, total, nil
+}
+
+// Cursor 游标分页查询数据
+func (r *Repository[T]) Cursor(req *query.CursorReq, scopes ...Scope) ([]*T, *query.CursorMeta, *int64, error) {
+	var list []*T
+
+	res := r.query(scopes...).Scopes(req.Filter)
+
+	// 获取总数，逻辑删除表或带作用域时估算值（整表行数）不准确
+	total, err := req.Total(res, r.TableName(), r.SoftDelete() || len(scopes) > 0)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// 游标查询
+	meta, err := query.FindCursor(res, req, &list)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return list, meta, total, nil
+}
+
+// BatchCreate 批量创建记录，batchSize <= 0 时一次性插入
+func (r *Repository[T]) BatchCreate(list []*T, batchSize int) error {
+	if len(list) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(list)
+	}
+	return r.DB().CreateInBatches(list, batchSize).Error
+}
+
+// Upsert 插入记录，conflictColumns 冲突时更新 updateColumns，未指定 updateColumns 时更新全部列
+func (r *Repository[T]) Upsert(v *T, conflictColumns []string, updateColumns ...string) error {
+	if len(conflictColumns) == 0 {
+		return errors.New("upsert requires conflict columns")
+	}
+	onConflict := clause.OnConflict{}
+	for _, c := range conflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: c})
+	}
+	if len(updateColumns) > 0 {
+		onConflict.DoUpdates = clause.AssignmentColumns(updateColumns)
+	} else {
+		onConflict.UpdateAll = true
+	}
+	return r.DB().Clauses(onConflict).Create(v).Error
+}
@@ -0,0 +1,222 @@
+package model
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/log"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newTestRepo 基于 SQLite 内存数据源创建示例表仓储，每个测试使用独立的数据库
+func newTestRepo(t *testing.T) *Repository[Demo] {
+	t.Helper()
+	log.Logger = zap.NewNop()
+	config.Conf = &config.Config{Sqlite: config.SqliteConfig{Path: ":memory:"}}
+	g := db.InitSqlite()
+	if err := g.AutoMigrate(&Demo{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := g.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return NewRepository[Demo](g)
+}
+
+func createDemo(t *testing.T, repo *Repository[Demo], address string) *Demo {
+	t.Helper()
+	d := &Demo{Address: address}
+	if err := repo.Create(d); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestRepositorySoftDelete(t *testing.T) {
+	repo := newTestRepo(t)
+	live := createDemo(t, repo, "live")
+	gone := createDemo(t, repo, "gone")
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Type: "wallet", Subject: "0xabc"})
+	if err := repo.WithContext(ctx).DeleteById(gone.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.GetById(gone.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetById deleted = %v, want ErrRecordNotFound", err)
+	}
+	for _, c := range []struct {
+		name   string
+		scopes []Scope
+		want   int64
+	}{
+		{"default", nil, 1},
+		{"include deleted", []Scope{IncludeDeleted}, 2},
+		{"only deleted", []Scope{OnlyDeleted}, 1},
+	} {
+		n, err := repo.Count(c.scopes...)
+		if err != nil || n != c.want {
+			t.Errorf("Count %s = %d, %v, want %d", c.name, n, err, c.want)
+		}
+	}
+
+	list, err := repo.List(OnlyDeleted)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List only deleted = %v, %v", list, err)
+	}
+	d := list[0]
+	if d.DeletedAt == nil || d.DeletedBy != "wallet:0xabc" || d.Version != 2 {
+		t.Errorf("deleted row = at %v by %q version %d, want deleted_at set, by wallet:0xabc, version 2", d.DeletedAt, d.DeletedBy, d.Version)
+	}
+
+	// 恢复只作用于已删除的记录
+	if err := repo.RestoreById(live.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("RestoreById live = %v, want ErrRecordNotFound", err)
+	}
+	if err := repo.RestoreById(gone.ID); err != nil {
+		t.Fatal(err)
+	}
+	d, err = repo.GetById(gone.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Deleted || d.DeletedAt != nil || d.DeletedBy != "" || d.Version != 3 {
+		t.Errorf("restored row = %+v", d)
+	}
+}
+
+func TestRepositoryPurge(t *testing.T) {
+	repo := newTestRepo(t)
+	live := createDemo(t, repo, "live")
+	gone := createDemo(t, repo, "gone")
+	if err := repo.DeleteById(gone.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.PurgeById(live.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("PurgeById live = %v, want ErrRecordNotFound", err)
+	}
+	if err := repo.PurgeById(gone.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := repo.Count(IncludeDeleted); n != 1 {
+		t.Errorf("Count after purge = %d, want 1", n)
+	}
+}
+
+func TestRepositoryDeleteMissing(t *testing.T) {
+	repo := newTestRepo(t)
+	d := createDemo(t, repo, "once")
+	if err := repo.DeleteById(d.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []int64{d.ID, d.ID + 100} {
+		if err := repo.DeleteById(id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("DeleteById(%d) = %v, want ErrRecordNotFound", id, err)
+		}
+	}
+}
+
+func TestRepositoryPurgeDeleted(t *testing.T) {
+	repo := newTestRepo(t)
+	live := createDemo(t, repo, "live")
+	var expired []int64
+	for i := 0; i < 3; i++ {
+		d := createDemo(t, repo, "expired")
+		expired = append(expired, d.ID)
+	}
+	recent := createDemo(t, repo, "recent")
+	for _, id := range append(expired, recent.ID) {
+		if err := repo.DeleteById(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := repo.DB().Model(&Demo{}).Where("id IN ?", expired).
+		Update("deleted_at", time.Now().Add(-48*time.Hour)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Now().Add(-24 * time.Hour)
+	for _, want := range []int64{2, 1, 0} {
+		n, err := repo.PurgeDeleted(before, 2)
+		if err != nil || n != want {
+			t.Fatalf("PurgeDeleted = %d, %v, want %d", n, err, want)
+		}
+	}
+	// 未过期的已删除记录及未删除记录保留
+	if _, err := repo.GetById(live.ID); err != nil {
+		t.Errorf("live row purged: %v", err)
+	}
+	if n, _ := repo.Count(OnlyDeleted); n != 1 {
+		t.Errorf("Count only deleted = %d, want 1", n)
+	}
+}
+
+func TestRepositoryUpdateVersion(t *testing.T) {
+	repo := newTestRepo(t)
+	d := createDemo(t, repo, "a")
+	stale := *d
+
+	d.Address = "b"
+	if err := repo.UpdateById(d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Version != 2 {
+		t.Errorf("version after update = %d, want 2", d.Version)
+	}
+
+	stale.Address = "c"
+	if err := repo.UpdateById(&stale); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("UpdateById stale = %v, want ErrVersionConflict", err)
+	}
+	if stale.Version != 1 {
+		t.Errorf("stale version after conflict = %d, want 1", stale.Version)
+	}
+	got, err := repo.GetById(d.ID)
+	if err != nil || got.Address != "b" {
+		t.Fatalf("GetById = %+v, %v, want address b", got, err)
+	}
+
+	// 已逻辑删除的记录不会被更新恢复
+	if err := repo.DeleteById(d.ID); err != nil {
+		t.Fatal(err)
+	}
+	got.Address = "zombie"
+	got.Version = 0
+	if err := repo.UpdateById(got); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateById deleted = %v, want ErrRecordNotFound", err)
+	}
+}
+
+func TestRepositoryUpsert(t *testing.T) {
+	repo := newTestRepo(t)
+	d := createDemo(t, repo, "a")
+
+	if err := repo.Upsert(&Demo{ID: d.ID, Address: "b", Version: 1}, []string{"id"}, "address"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Upsert(&Demo{ID: 100, Address: "new", Version: 1}, []string{"id"}, "address"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Upsert(&Demo{Address: "c"}, nil); err == nil {
+		t.Error("Upsert without conflict columns succeeded")
+	}
+
+	got, err := repo.GetById(d.ID)
+	if err != nil || got.Address != "b" {
+		t.Errorf("GetById upserted = %+v, %v, want address b", got, err)
+	}
+	if n, _ := repo.Count(); n != 2 {
+		t.Errorf("Count = %d, want 2", n)
+	}
+}
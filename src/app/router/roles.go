@@ -0,0 +1,9 @@
+package router
+
+import "bossfi-backend/src/core/auth"
+
+// 业务角色声明，内置 admin 拥有全部权限；角色通过 apikey/role 命令或数据库绑定到钱包地址及 API Key
+func init() {
+	auth.DefineRole("operator", "demo:*", "evm:read", "sys:read")
+	auth.DefineRole("viewer", "demo:read", "evm:read")
+}
@@ -2,32 +2,73 @@ package router
 
 import (
 	"bossfi-backend/src/app/api"
+	"bossfi-backend/src/core/auth"
 	"bossfi-backend/src/core/config"
 	"bossfi-backend/src/core/ctx"
+	"bossfi-backend/src/core/gin/middleware"
+	"bossfi-backend/src/core/result"
+	"bossfi-backend/src/docs"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 func Bind(r *gin.Engine, ctx *ctx.Context) {
-	// 注册 swagger 路由
+	// 注册 swagger 路由，业务状态码表写入文档描述
+	docs.SwaggerInfo.Description = "## 业务状态码\n\n" + result.ExportMarkdown()
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	v := r.Group("/api/" + config.Conf.App.Version)
 
 	{
+		// API Key 调用需要对应权限，匿名及钱包登录不受限制；删除、恢复仅限拥有 demo:delete 权限的角色，永久删除仅限管理员
 		demoApi := api.NewDemoApi()
-		v.GET("/demo/page", demoApi.Page)
-		v.POST("/demo/create", demoApi.Create)
-		v.GET("/demo/:id", demoApi.GetById)
-		v.PUT("/demo/:id", demoApi.Update)
-		v.DELETE("/demo/:id", demoApi.Delete)
-		v.GET("/demo/list", demoApi.List)
+		del := middleware.RequirePermission("demo:delete")
+		read, write := middleware.ApiKeyScope("demo:read"), middleware.ApiKeyScope("demo:write")
+		v.GET("/demo/page", read, demoApi.Page)
+		v.GET("/demo/cursor", read, demoApi.Cursor)
+		v.GET("/demo/deleted", del, demoApi.Deleted)
+		v.POST("/demo/create", write, demoApi.Create)
+		v.GET("/demo/:id", read, demoApi.GetById)
+		v.PUT("/demo/:id", write, demoApi.Update)
+		v.PATCH("/demo/:id", write, demoApi.Patch)
+		v.DELETE("/demo/:id", del, demoApi.Delete)
+		v.POST("/demo/:id/restore", del, demoApi.Restore)
+		v.DELETE("/demo/:id/purge", middleware.RequireRole(auth.RoleAdmin), demoApi.Purge)
+		v.GET("/demo/list", read, demoApi.List)
 	}
 
 	{
 		evmApi := api.NewEvmApi()
-		v.GET("/evm/get_block_by_num/:block_num", evmApi.GetBlockByNum)
+		read := middleware.ApiKeyScope("evm:read")
+		v.GET("/evm/get_block_by_num/:block_num", read, evmApi.GetBlockByNum)
+		v.GET("/evm/get_tx_by_hash/:hash", read, evmApi.GetTransaction)
+		v.GET("/evm/get_receipt/:hash", read, evmApi.GetReceipt)
+	}
+
+	{
+		apiKeyApi := api.NewApiKeyApi()
+		admin := middleware.RequirePermission("apikey:admin")
+		// 响应包含 API Key 明文，不记录到请求日志
+		v.POST("/auth/api_keys", admin, middleware.NoResponseLog(), apiKeyApi.Create)
+		v.GET("/auth/api_keys", admin, apiKeyApi.List)
+		v.DELETE("/auth/api_keys/:id", admin, apiKeyApi.Revoke)
+
+		authApi := api.NewAuthApi()
+		v.GET("/auth/permissions", authApi.Permissions)
+		v.POST("/auth/wallet/nonce", authApi.WalletNonce)
+		// 响应包含会话 token，不记录到请求日志
+		v.POST("/auth/wallet/login", middleware.NoResponseLog(), authApi.WalletLogin)
+		v.POST("/auth/logout", authApi.Logout)
+	}
+
+	{
+		sysApi := api.NewSysApi()
+		v.GET("/sys/codes", sysApi.Codes)
+		v.GET("/sys/mq/dead_letters", middleware.RequirePermission("sys:read"), sysApi.DeadLetters)
+
+		auditApi := api.NewAuditApi()
+		v.GET("/sys/audit_logs", middleware.RequirePermission("audit:read"), auditApi.Page)
 	}
 
 }
@@ -2,6 +2,10 @@ package service
 
 import (
 	"bossfi-backend/src/app/model"
+	"bossfi-backend/src/core/patch"
+	"bossfi-backend/src/core/query"
+	"context"
+	"time"
 )
 
 type DemoService struct {
@@ -13,31 +17,91 @@ func NewDemoService() *DemoService {
 }
 
 // Create 创建记录
-func (s *DemoService) Create(demo *model.Demo) error {
-	return s.dao.Create(demo)
+func (s *DemoService) Create(ctx context.Context, demo *model.Demo) error {
+	return s.dao.WithContext(ctx).Create(demo)
 }
 
 // GetById 查询单条记录
-func (s *DemoService) GetById(id int64) (*model.Demo, error) {
-	return s.dao.GetById(id)
+func (s *DemoService) GetById(ctx context.Context, id int64) (*model.Demo, error) {
+	return s.dao.WithContext(ctx).GetById(id)
 }
 
-// Update 更新记录
-func (s *DemoService) Update(demo *model.Demo) error {
-	return s.dao.UpdateById(demo)
+// Update 更新可修改的字段，版本号与当前记录不一致时返回 model.ErrVersionConflict
+func (s *DemoService) Update(ctx context.Context, id int64, req *model.DemoUpdate) (*model.Demo, error) {
+	dao := s.dao.WithContext(ctx)
+	demo, err := dao.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	req.ApplyTo(demo)
+	return s.save(ctx, demo)
+}
+
+// Patch 按 JSON Merge Patch 部分更新，patch 只能包含 model.DemoUpdate 的字段，
+// 包含其他字段返回 patch.ErrNotWritable；未指定 version 时以读取到的版本为准
+func (s *DemoService) Patch(ctx context.Context, id int64, p []byte) (*model.Demo, error) {
+	dao := s.dao.WithContext(ctx)
+	demo, err := dao.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	req := model.NewDemoUpdate(demo)
+	if err := patch.Apply(req, p); err != nil {
+		return nil, err
+	}
+	req.ApplyTo(demo)
+	return s.save(ctx, demo)
+}
+
+// save 按主键及版本号更新，返回更新后的记录
+func (s *DemoService) save(ctx context.Context, demo *model.Demo) (*model.Demo, error) {
+	dao := s.dao.WithContext(ctx)
+	if err := dao.UpdateById(demo); err != nil {
+		return nil, err
+	}
+	return dao.GetById(demo.ID)
 }
 
 // Delete 软删除记录
-func (s *DemoService) Delete(id int64) error {
-	return s.dao.DeleteById(id)
+func (s *DemoService) Delete(ctx context.Context, id int64) error {
+	return s.dao.WithContext(ctx).DeleteById(id)
+}
+
+// Restore 恢复已删除的记录，记录不存在或未删除时返回 gorm.ErrRecordNotFound
+func (s *DemoService) Restore(ctx context.Context, id int64) (*model.Demo, error) {
+	dao := s.dao.WithContext(ctx)
+	if err := dao.RestoreById(id); err != nil {
+		return nil, err
+	}
+	return dao.GetById(id)
+}
+
+// Purge 永久删除已删除的记录，记录不存在或未删除时返回 gorm.ErrRecordNotFound
+func (s *DemoService) Purge(ctx context.Context, id int64) error {
+	return s.dao.WithContext(ctx).PurgeById(id)
+}
+
+// PurgeExpired 永久删除删除时间早于 before 的记录，每次最多 limit 条
+func (s *DemoService) PurgeExpired(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return s.dao.WithContext(ctx).PurgeDeleted(before, limit)
+}
+
+// Deleted 分页查询已删除记录
+func (s *DemoService) Deleted(ctx context.Context, req *query.PageReq) ([]*model.Demo, int64, error) {
+	return s.dao.WithContext(ctx).Page(req, model.OnlyDeleted)
 }
 
 // List 查询所有未删除记录
-func (s *DemoService) List() ([]*model.Demo, error) {
-	return s.dao.List()
+func (s *DemoService) List(ctx context.Context) ([]*model.Demo, error) {
+	return s.dao.WithContext(ctx).List()
 }
 
 // Page 查询分页数据
-func (s *DemoService) Page(page, pageSize int) ([]*model.Demo, int64, error) {
-	return s.dao.Page(page, pageSize)
+func (s *DemoService) Page(ctx context.Context, req *query.PageReq) ([]*model.Demo, int64, error) {
+	return s.dao.WithContext(ctx).Page(req)
+}
+
+// Cursor 游标分页查询数据
+func (s *DemoService) Cursor(ctx context.Context, req *query.CursorReq) ([]*model.Demo, *query.CursorMeta, *int64, error) {
+	return s.dao.WithContext(ctx).Cursor(req)
 }
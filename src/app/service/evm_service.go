@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bossfi-backend/src/core/cache"
+	"bossfi-backend/src/core/chainclient/domain"
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/ctx"
+	"bossfi-backend/src/core/log"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultChainFinalTTL 已终局数据默认缓存时间
+	defaultChainFinalTTL = 24 * time.Hour
+	// defaultChainUnfinalTTL 未终局数据默认缓存时间，约一个出块间隔
+	defaultChainUnfinalTTL = 12 * time.Second
+	// defaultConfirmations 节点不支持 finalized 标签时的默认终局确认数
+	defaultConfirmations = 64
+	// blockHashTTL 未终局高度的区块哈希记录保留时间，用于检测重组
+	blockHashTTL = time.Hour
+)
+
+// ErrUnsupportedChain 未配置的链
+var ErrUnsupportedChain = errors.New("unsupported chain id")
+
+// EvmService 链上数据查询，已终局的区块/交易/收据长期缓存，未终局的短暂缓存，检测到重组时失效全部未终局缓存
+type EvmService struct{}
+
+func NewEvmService() *EvmService {
+	return &EvmService{}
+}
+
+// GetBlockByNum 按高度查询区块
+func (s *EvmService) GetBlockByNum(c context.Context, chainId int, num uint64) (*domain.Block, error) {
+	client, err := s.client(chainId)
+	if err != nil {
+		return nil, err
+	}
+	key := s.key(chainId, "block", strconv.FormatUint(num, 10))
+	return cache.GetOrLoadTTL(c, cache.Default, key, func(c context.Context) (*domain.Block, time.Duration, []string, error) {
+		block, err := client.BlockByNumber(c, new(big.Int).SetUint64(num))
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		final := s.isFinal(c, client, chainId, num)
+		if !final {
+			s.checkReorg(c, chainId, num, block.Hash().Hex(), block.ParentHash().Hex())
+		}
+		ttl, tags := s.ttl(chainId, final)
+		return domain.ToBlock(block), ttl, tags, nil
+	})
+}
+
+// GetTransaction 按哈希查询交易，pending 交易不缓存
+func (s *EvmService) GetTransaction(c context.Context, chainId int, hash string) (*domain.Transaction, error) {
+	client, err := s.client(chainId)
+	if err != nil {
+		return nil, err
+	}
+	// 哈希统一为小写 0x 开头的格式，大小写、是否带 0x 不同的同一哈希共用缓存
+	txHash := common.HexToHash(hash)
+	key := s.key(chainId, "tx", txHash.Hex())
+	return cache.GetOrLoadTTL(c, cache.Default, key, func(c context.Context) (*domain.Transaction, time.Duration, []string, error) {
+		tx, pending, err := client.TransactionByHash(c, txHash)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if pending {
+			return domain.ToTransaction(tx), -1, nil, nil
+		}
+		// 交易本身不含区块高度，通过收据判断终局（收据同时被缓存）
+		receipt, err := s.GetReceipt(c, chainId, txHash.Hex())
+		if err != nil {
+			return domain.ToTransaction(tx), -1, nil, nil
+		}
+		ttl, tags := s.ttl(chainId, s.isFinal(c, client, chainId, receipt.BlockNumber))
+		return domain.ToTransaction(tx), ttl, tags, nil
+	})
+}
+
+// GetReceipt 按交易哈希查询收据
+func (s *EvmService) GetReceipt(c context.Context, chainId int, hash string) (*domain.Receipt, error) {
+	client, err := s.client(chainId)
+	if err != nil {
+		return nil, err
+	}
+	txHash := common.HexToHash(hash)
+	key := s.key(chainId, "receipt", txHash.Hex())
+	return cache.GetOrLoadTTL(c, cache.Default, key, func(c context.Context) (*domain.Receipt, time.Duration, []string, error) {
+		receipt, err := client.TransactionReceipt(c, txHash)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		num := receipt.BlockNumber.Uint64()
+		final := s.isFinal(c, client, chainId, num)
+		if !final {
+			s.checkReorg(c, chainId, num, receipt.BlockHash.Hex(), "")
+		}
+		ttl, tags := s.ttl(chainId, final)
+		return domain.ToReceipt(receipt), ttl, tags, nil
+	})
+}
+
+// client 获取链客户端
+func (s *EvmService) client(chainId int) (*ethclient.Client, error) {
+	if _, ok := ctx.Ctx.ChainMap[chainId]; !ok {
+		return nil, ErrUnsupportedChain
+	}
+	return ctx.GetEvmClient(chainId), nil
+}
+
+func (s *EvmService) key(chainId int, parts ...string) string {
+	return cache.Key(append([]string{"evm", strconv.Itoa(chainId)}, parts...)...)
+}
+
+// unfinalTag 未终局数据标签，发生重组时整体失效
+func (s *EvmService) unfinalTag(chainId int) string {
+	return s.key(chainId, "unfinal")
+}
+
+// ttl 根据是否终局返回缓存时间及标签
+func (s *EvmService) ttl(chainId int, final bool) (time.Duration, []string) {
+	conf := config.Conf.Cache
+	if final {
+		if conf.ChainFinalTTL > 0 {
+			return time.Duration(conf.ChainFinalTTL) * time.Second, nil
+		}
+		return defaultChainFinalTTL, nil
+	}
+	ttl := defaultChainUnfinalTTL
+	if conf.ChainUnfinalTTL > 0 {
+		ttl = time.Duration(conf.ChainUnfinalTTL) * time.Second
+	}
+	return ttl, []string{s.unfinalTag(chainId)}
+}
+
+// IsFinal 区块高度是否已终局（终局高度有短暂缓存），查询失败时按未终局处理
+func (s *EvmService) IsFinal(c context.Context, chainId int, num uint64) bool {
+	client, err := s.client(chainId)
+	if err != nil {
+		return false
+	}
+	return s.isFinal(c, client, chainId, num)
+}
+
+// isFinal 区块高度是否已终局，查询失败时按未终局处理
+func (s *EvmService) isFinal(c context.Context, client *ethclient.Client, chainId int, num uint64) bool {
+	finalized, err := s.finalized(c, client, chainId)
+	if err != nil {
+		log.Logger.Warn("get finalized block number error", zap.Int("chain_id", chainId), zap.Error(err))
+		return false
+	}
+	return num <= finalized
+}
+
+// finalized 最新终局高度，缓存一个未终局周期；节点不支持 finalized 标签时按确认数推算
+func (s *EvmService) finalized(c context.Context, client *ethclient.Client, chainId int) (uint64, error) {
+	ttl, _ := s.ttl(chainId, false)
+	return cache.GetOrLoad(c, cache.Default, s.key(chainId, "finalized"), ttl, func(c context.Context) (uint64, error) {
+		header, err := client.HeaderByNumber(c, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+		if err == nil {
+			return header.Number.Uint64(), nil
+		}
+		latest, err := client.BlockNumber(c)
+		if err != nil {
+			return 0, err
+		}
+		confirmations := s.confirmations(chainId)
+		if latest < confirmations {
+			return 0, nil
+		}
+		return latest - confirmations, nil
+	})
+}
+
+func (s *EvmService) confirmations(chainId int) uint64 {
+	for _, chain := range config.Conf.Chains {
+		if chain.ChainId == chainId && chain.Confirmations > 0 {
+			return chain.Confirmations
+		}
+	}
+	return defaultConfirmations
+}
+
+// checkReorg 对比已记录的区块哈希检测重组：同一高度哈希变化或父哈希与上一高度记录不一致时，
+// 失效该链全部未终局缓存，并记录新的区块哈希
+func (s *EvmService) checkReorg(c context.Context, chainId int, num uint64, hash string, parentHash string) {
+	reorg := false
+	if prev, err := cache.Get[string](c, cache.Default, s.hashKey(chainId, num)); err == nil && prev != hash {
+		reorg = true
+	}
+	if parentHash != "" && num > 0 {
+		if parent, err := cache.Get[string](c, cache.Default, s.hashKey(chainId, num-1)); err == nil && parent != parentHash {
+			reorg = true
+		}
+	}
+
+	if reorg {
+		log.Logger.Warn("chain reorg detected, invalidate unfinalized cache",
+			zap.Int("chain_id", chainId), zap.Uint64("block_num", num), zap.String("hash", hash))
+		if err := cache.Default.InvalidateTags(c, s.unfinalTag(chainId)); err != nil {
+			log.Logger.Error("invalidate unfinalized cache error", zap.Int("chain_id", chainId), zap.Error(err))
+		}
+	}
+	_ = cache.Set(c, cache.Default, s.hashKey(chainId, num), hash, blockHashTTL, s.unfinalTag(chainId))
+}
+
+func (s *EvmService) hashKey(chainId int, num uint64) string {
+	return s.key(chainId, "hash", strconv.FormatUint(num, 10))
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"bossfi-backend/src/core/auth"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// runApiKey API Key 管理，用于创建首个管理 key 及运维
+// 用法: apikey create -name n -owner o [-scopes a,b] [-expires 720h] [-rate 1000/60] | list [-owner o] | revoke -id 1
+func runApiKey(configFile string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: apikey create -name n -owner o [-scopes a,b] [-expires 720h] [-rate 1000/60] | list [-owner o] | revoke -id 1")
+	}
+
+	initConfig(configFile)
+	initLog()
+	initDataSources()
+
+	ctx := context.Background()
+	fs := flag.NewFlagSet("apikey "+args[0], flag.ContinueOnError)
+	switch args[0] {
+	case "create":
+		name := fs.String("name", "", "key name")
+		owner := fs.String("owner", "", "key owner")
+		scopes := fs.String("scopes", "", "comma separated scopes, e.g. evm:read,demo:write")
+		expires := fs.Duration("expires", 0, "expire after duration, 0 means never")
+		rate := fs.String("rate", "", "rate limit limit/seconds, e.g. 1000/60")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *name == "" || *owner == "" {
+			return fmt.Errorf("-name and -owner are required")
+		}
+
+		req := &auth.ApiKeyCreate{Name: *name, Owner: *owner, Scopes: []string{}}
+		for _, s := range strings.Split(*scopes, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				req.Scopes = append(req.Scopes, s)
+			}
+		}
+		if *expires > 0 {
+			t := time.Now().Add(*expires)
+			req.ExpireTime = &t
+		}
+		if *rate != "" {
+			limit, period, err := auth.ParseRate(*rate)
+			if err != nil {
+				return err
+			}
+			req.RateLimit, req.RatePeriod = limit, period
+		}
+
+		key, plain, err := auth.CreateApiKey(ctx, req)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("id:     %d\nprefix: %s\nscopes: %s\nkey:    %s\n", key.ID, key.Prefix, strings.Join(key.Scopes, ","), plain)
+		fmt.Println("the key is shown only once, store it securely")
+		return nil
+	case "list":
+		owner := fs.String("owner", "", "filter by owner")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		list, err := auth.ListApiKeys(ctx, *owner)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	case "revoke":
+		id := fs.Int64("id", 0, "key id")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *id <= 0 {
+			return fmt.Errorf("-id is required")
+		}
+		return auth.RevokeApiKey(ctx, *id)
+	default:
+		return fmt.Errorf("unknown apikey command %q", args[0])
+	}
+}
@@ -1,17 +1,38 @@
 package core
 
 import (
+	"bossfi-backend/src/app/job"
 	appRouter "bossfi-backend/src/app/router"
+	"bossfi-backend/src/common"
+	"bossfi-backend/src/core/audit"
+	"bossfi-backend/src/core/cache"
 	"bossfi-backend/src/core/chainclient"
 	"bossfi-backend/src/core/config"
 	"bossfi-backend/src/core/ctx"
 	"bossfi-backend/src/core/db"
 	"bossfi-backend/src/core/gin/router"
+	"bossfi-backend/src/core/lock"
 	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/mq"
+	"bossfi-backend/src/core/report"
+	"bossfi-backend/src/core/result"
+	"context"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	// shutdownTimeout 退出时等待处理中请求完成的时间
+	shutdownTimeout = 10 * time.Second
+	// reportFlushTimeout 退出时等待错误事件上报完成的时间
+	reportFlushTimeout = 5 * time.Second
 )
 
 func Start(configFile string) {
@@ -19,12 +40,30 @@ func Start(configFile string) {
 	initConfig(configFile)
 	// 初始化日志组件
 	initLog()
+	// 校验业务状态码
+	initResult()
+	// 初始化错误上报
+	initReport()
 	// 启用性能监控组件
 	initPprof()
+	// 校验依赖 PostgreSQL 的功能
+	checkPgsqlFeatures()
 	// 初始化数据库/Redis
 	initDB()
+	// 数据库迁移
+	initMigrate()
+	// 初始化审计日志
+	initAudit()
+	// 初始化缓存
+	initCache()
+	// 初始化分布式锁
+	initLock()
+	// 初始化消息队列
+	initMQ()
 	// 初始化区块链客户端
 	initChainClient()
+	// 启动定时任务
+	initJob()
 	// 初始化Gin
 	initGin()
 }
@@ -33,6 +72,34 @@ func initConfig(configFile string) {
 	ctx.Ctx.Config = config.InitConfig(configFile)
 }
 
+func initResult() {
+	if err := result.Check(); err != nil {
+		log.Logger.Error("check result codes error", zap.Error(err))
+		panic(err)
+	}
+}
+
+func initReport() {
+	conf := config.Conf.Report
+	switch conf.Driver {
+	case report.DriverNone:
+		report.Default = report.Nop{}
+	case "", report.DriverFile:
+		path := conf.Path
+		if path == "" {
+			path = common.GetCurrentAbPath() + "/logs/" + config.Conf.App.Name + "-errors.log"
+		}
+		r, err := report.NewFile(path)
+		if err != nil {
+			log.Logger.Error("init report error", zap.Error(err))
+			panic(err)
+		}
+		report.Default = r
+	default:
+		panic("unknown report driver " + conf.Driver)
+	}
+}
+
 func initPprof() {
 	if !config.Conf.Monitor.PprofEnable {
 		return
@@ -53,10 +120,73 @@ func initLog() {
 }
 
 func initDB() {
-	ctx.Ctx.DB = db.InitPgsql()
+	initDataSources()
 	ctx.Ctx.Redis = db.InitRedis()
 }
 
+func initDataSources() {
+	ctx.Ctx.DB = db.InitDataSources()
+}
+
+// checkPgsqlFeatures 迁移脚本（advisory lock、PostgreSQL 语法）及审计日志（jsonb）依赖 PostgreSQL，
+// 开启时主数据源须为 pgsql，启动时即失败而不是运行中出错
+func checkPgsqlFeatures() {
+	primary := db.PrimaryName()
+	if primary == db.SourcePgsql {
+		return
+	}
+	if autoMigrate() {
+		panic(fmt.Sprintf("auto_migrate requires the pgsql primary data source, got %q", primary))
+	}
+	if config.Conf.Audit.Enable {
+		panic(fmt.Sprintf("audit requires the pgsql primary data source, got %q", primary))
+	}
+}
+
+func initAudit() {
+	if !config.Conf.Audit.Enable {
+		return
+	}
+	if err := audit.Register(ctx.Ctx.DB, config.Conf.Audit.MaxRows); err != nil {
+		log.Logger.Error("init audit error", zap.Error(err))
+		panic(err)
+	}
+}
+
+func initCache() {
+	conf := config.Conf.Cache
+	cache.Default = cache.New(ctx.Ctx.Redis,
+		cache.WithNamespace(config.Conf.App.Name),
+		cache.WithCodec(cache.CodecByName(conf.Codec)),
+		cache.WithLocal(conf.LocalSize, time.Duration(conf.LocalTTL)*time.Second),
+	)
+	ctx.Ctx.Cache = cache.Default
+}
+
+func initLock() {
+	lock.Default = lock.New(ctx.Ctx.Redis, config.Conf.App.Name)
+	ctx.Ctx.Lock = lock.Default
+}
+
+func initMQ() {
+	conf := config.Conf.MQ
+	opts := mq.Options{
+		MaxRetries: conf.MaxRetries,
+		RetryDelay: time.Duration(conf.RetryDelay) * time.Second,
+		MaxLen:     conf.MaxLen,
+		Batch:      conf.Batch,
+	}
+	switch conf.Driver {
+	case mq.DriverMemory:
+		mq.Default = mq.NewMemory(opts)
+	case "", mq.DriverRedis:
+		mq.Default = mq.NewRedis(ctx.Ctx.Redis, config.Conf.App.Name, opts)
+	default:
+		panic("unknown mq driver " + conf.Driver)
+	}
+	ctx.Ctx.MQ = mq.Default
+}
+
 func initChainClient() {
 	chainMap := make(map[int]*chainclient.ChainClient)
 	for _, chain := range config.Conf.Chains {
@@ -72,12 +202,42 @@ func initChainClient() {
 	ctx.Ctx.ChainMap = chainMap
 }
 
+func initJob() {
+	job.StartRetention(context.Background())
+}
+
 func initGin() {
 	r := router.InitRouter()
 	ctx.Ctx.Gin = r
 	appRouter.Bind(r, &ctx.Ctx)
-	err := r.Run(":" + ctx.Ctx.Config.App.Port)
-	if err != nil {
+
+	srv := &http.Server{Addr: ":" + ctx.Ctx.Config.App.Port, Handler: r}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-errCh:
+		flushReport()
 		panic(err)
+	case sig := <-quit:
+		log.Logger.Info("shutdown", zap.String("signal", sig.String()))
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Logger.Error("shutdown http server error", zap.Error(err))
+	}
+	flushReport()
+}
+
+// flushReport 退出前等待错误事件上报完成
+func flushReport() {
+	if report.Default != nil && !report.Default.Flush(reportFlushTimeout) {
+		log.Logger.Warn("flush report timeout")
 	}
+	_ = log.Logger.Sync()
 }
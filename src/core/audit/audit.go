@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/query"
+	"context"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+// 操作类型
+const (
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionDelete     = "delete"
+	ActionSoftDelete = "soft_delete"
+	ActionRestore    = "restore"
+)
+
+// 操作者类型，已认证时为认证主体类型（wallet/api_key）
+const (
+	ActorAnonymous = "anonymous" // 未认证的请求
+	ActorSystem    = "system"    // 非请求触发（定时任务、命令行等）
+)
+
+// Log 审计日志，只允许追加
+type Log struct {
+	ID         int64                  `json:"id" gorm:"column:id;primaryKey"`
+	ActorType  string                 `json:"actor_type" gorm:"column:actor_type"`
+	Actor      string                 `json:"actor" gorm:"column:actor"`
+	Method     string                 `json:"method" gorm:"column:method"`
+	Route      string                 `json:"route" gorm:"column:route"`
+	Action     string                 `json:"action" gorm:"column:action"`
+	Entity     string                 `json:"entity" gorm:"column:entity"`
+	EntityId   string                 `json:"entity_id" gorm:"column:entity_id"`
+	Before     map[string]interface{} `json:"before" gorm:"column:before;type:jsonb;serializer:json"`
+	After      map[string]interface{} `json:"after" gorm:"column:after;type:jsonb;serializer:json"`
+	Ip         string                 `json:"ip" gorm:"column:ip"`
+	RequestId  string                 `json:"request_id" gorm:"column:request_id"`
+	CreateTime time.Time              `json:"create_time" gorm:"column:create_time;autoCreateTime"`
+}
+
+func (Log) TableName() string {
+	return "bossfi_audit_log"
+}
+
+// Request 触发写操作的请求信息，由审计中间件写入请求 context
+type Request struct {
+	Method    string
+	Route     string
+	Ip        string
+	RequestId string
+}
+
+type requestKey struct{}
+
+// WithRequest 将请求信息写入 context
+func WithRequest(ctx context.Context, r *Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// RequestFromContext 获取 context 中的请求信息，非请求触发时返回 nil
+func RequestFromContext(ctx context.Context) *Request {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(requestKey{}).(*Request)
+	return r
+}
+
+// Actor context 中的操作者类型及标识：已认证时为认证主体，请求未认证时为 anonymous，非请求触发时为 system
+func Actor(ctx context.Context) (string, string) {
+	if p := auth.FromContext(ctx); p != nil {
+		return p.Type, p.Subject
+	}
+	if RequestFromContext(ctx) != nil {
+		return ActorAnonymous, ""
+	}
+	return ActorSystem, ""
+}
+
+var tracked sync.Map // 表名 -> struct{}
+
+// Track 记录表的写操作，表需有单一主键
+//
+//	func init() { audit.Track(Demo{}.TableName()) }
+func Track(tables ...string) {
+	for _, t := range tables {
+		tracked.Store(t, struct{}{})
+	}
+}
+
+// Tracked 表是否记录审计日志
+func Tracked(table string) bool {
+	_, ok := tracked.Load(table)
+	return ok
+}
+
+// PageOptions 审计日志分页查询可排序/过滤字段，create_time 支持 range 过滤时间范围
+var PageOptions = query.Options{
+	DefaultSort: "-id",
+	SortFields: map[string]string{
+		"id":          "id",
+		"create_time": "create_time",
+	},
+	FilterFields: map[string]string{
+		"actor_type":  "actor_type",
+		"actor":       "actor",
+		"action":      "action",
+		"entity":      "entity",
+		"entity_id":   "entity_id",
+		"route":       "route",
+		"request_id":  "request_id",
+		"create_time": "create_time",
+	},
+}
+
+// Page 分页查询审计日志
+func Page(ctx context.Context, req *query.PageReq) ([]*Log, int64, error) {
+	var list []*Log
+	var total int64
+	res := db.DB.WithContext(ctx).Model(&Log{}).Scopes(req.Filter)
+	if err := res.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := res.Scopes(req.Sort, req.Paginate).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
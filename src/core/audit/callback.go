@@ -0,0 +1,324 @@
+package audit
+
+import (
+	"bossfi-backend/src/core/log"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+	"reflect"
+)
+
+const (
+	// snapshotKey 更新/删除前的记录快照
+	snapshotKey = "audit:snapshot"
+	// defaultMaxRows 单条语句默认最多记录的行数
+	defaultMaxRows = 100
+	// beginCallback GORM 开启默认事务的回调，变更前快照在其之后查询
+	beginCallback = "gorm:begin_transaction"
+	// commitCallback GORM 提交/回滚默认事务的回调，审计日志在其之前写入，与业务写操作同时提交或回滚
+	commitCallback = "gorm:commit_or_rollback_transaction"
+)
+
+var (
+	// ErrTooManyRows 单条更新/删除语句影响的行数超过 max_rows，语句不执行
+	ErrTooManyRows = errors.New("audit: statement affects more rows than max_rows")
+	// ErrUnauditedRows 语句实际影响的行数多于变更前快照（快照后有新记录满足条件），回滚写操作
+	ErrUnauditedRows = errors.New("audit: statement affected rows missing from snapshot")
+)
+
+// maxRows 已注册的单条语句最多记录的行数，未注册时为 0
+var maxRows int
+
+// MaxRows 单条更新/删除语句最多影响的行数，未开启审计时返回 0；批量写操作（如定时清理）每批不应超过该值
+func MaxRows() int {
+	return maxRows
+}
+
+// record 一行记录的主键及字段值（列名 -> JSON 值）
+type record struct {
+	ID     interface{}
+	Values map[string]interface{}
+}
+
+type auditor struct {
+	maxRows int
+}
+
+// Register 注册审计回调：已 Track 的表的新增、更新、删除在同一事务内写入审计日志，写入失败时业务写操作一并回滚
+//
+// 更新/删除前在同一事务内按语句条件查询并锁定（SELECT ... FOR UPDATE）变更前的记录，保证快照与实际变更一致；
+// 单条语句超过 limit 行时返回 ErrTooManyRows，写操作不执行，不会只记录部分行
+func Register(db *gorm.DB, limit int) error {
+	if limit <= 0 {
+		limit = defaultMaxRows
+	}
+	maxRows = limit
+	a := &auditor{maxRows: limit}
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Before(commitCallback).Register("bossfi:audit", a.afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().After(beginCallback).Before("gorm:update").Register("bossfi:audit_snapshot", a.snapshot); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Before(commitCallback).Register("bossfi:audit", a.afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().After(beginCallback).Before("gorm:delete").Register("bossfi:audit_snapshot", a.snapshot); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Before(commitCallback).Register("bossfi:audit", a.afterDelete)
+}
+
+// enabled 当前语句是否记录审计日志
+func (a *auditor) enabled(tx *gorm.DB) bool {
+	s := tx.Statement
+	return tx.Error == nil && !tx.DryRun && s.Schema != nil && s.Schema.PrioritizedPrimaryField != nil && Tracked(s.Table)
+}
+
+func (a *auditor) afterCreate(tx *gorm.DB) {
+	if !a.enabled(tx) || tx.Statement.RowsAffected == 0 {
+		return
+	}
+	s := tx.Statement
+	var logs []*Log
+	eachModel(s.ReflectValue, func(v reflect.Value) {
+		r := a.record(s, v)
+		logs = append(logs, newLog(s, ActionCreate, r.ID, nil, r.Values))
+	})
+	a.write(tx, logs)
+}
+
+// snapshot 更新/删除前查询并锁定将被变更的记录，超过 maxRows 行时中止语句
+func (a *auditor) snapshot(tx *gorm.DB) {
+	if !a.enabled(tx) {
+		return
+	}
+	records, err := a.load(tx, a.conditions(tx.Statement), true)
+	if err != nil {
+		_ = tx.AddError(err)
+		return
+	}
+	if len(records) > a.maxRows {
+		log.Logger.Error("audit rows exceed limit", zap.String("table", tx.Statement.Table), zap.Int("limit", a.maxRows))
+		_ = tx.AddError(ErrTooManyRows)
+		return
+	}
+	tx.InstanceSet(snapshotKey, records)
+}
+
+// checkSnapshot 语句影响的行数不能多于快照，否则有变更未被记录
+func (a *auditor) checkSnapshot(tx *gorm.DB, before []*record) bool {
+	if tx.Statement.RowsAffected > int64(len(before)) {
+		log.Logger.Error("audit snapshot missing affected rows", zap.String("table", tx.Statement.Table),
+			zap.Int("snapshot", len(before)), zap.Int64("affected", tx.Statement.RowsAffected))
+		_ = tx.AddError(ErrUnauditedRows)
+		return false
+	}
+	return true
+}
+
+func (a *auditor) afterUpdate(tx *gorm.DB) {
+	if !a.enabled(tx) || tx.Statement.RowsAffected == 0 {
+		return
+	}
+	before := a.snapshotted(tx)
+	if !a.checkSnapshot(tx, before) {
+		return
+	}
+	s := tx.Statement
+	ids := make([]interface{}, 0, len(before))
+	for _, r := range before {
+		ids = append(ids, r.ID)
+	}
+	after, err := a.load(tx, []clause.Expression{clause.IN{Column: s.Schema.PrioritizedPrimaryField.DBName, Values: ids}}, false)
+	if err != nil {
+		_ = tx.AddError(err)
+		return
+	}
+	afterById := make(map[string]*record, len(after))
+	for _, r := range after {
+		afterById[fmt.Sprint(r.ID)] = r
+	}
+
+	var logs []*Log
+	for _, b := range before {
+		r, ok := afterById[fmt.Sprint(b.ID)]
+		if !ok {
+			continue
+		}
+		old, changed := diff(s, b.Values, r.Values)
+		if len(changed) == 0 {
+			continue
+		}
+		logs = append(logs, newLog(s, updateAction(old, changed), b.ID, old, changed))
+	}
+	a.write(tx, logs)
+}
+
+func (a *auditor) afterDelete(tx *gorm.DB) {
+	if !a.enabled(tx) || tx.Statement.RowsAffected == 0 {
+		return
+	}
+	before := a.snapshotted(tx)
+	if !a.checkSnapshot(tx, before) {
+		return
+	}
+	logs := make([]*Log, 0, len(before))
+	for _, b := range before {
+		logs = append(logs, newLog(tx.Statement, ActionDelete, b.ID, b.Values, nil))
+	}
+	a.write(tx, logs)
+}
+
+func (a *auditor) snapshotted(tx *gorm.DB) []*record {
+	v, ok := tx.InstanceGet(snapshotKey)
+	if !ok {
+		return nil
+	}
+	records, _ := v.([]*record)
+	return records
+}
+
+// conditions 语句的查询条件，模型中有主键值时加入主键条件（与 GORM 更新/删除时的处理一致）
+func (a *auditor) conditions(s *gorm.Statement) []clause.Expression {
+	var exprs []clause.Expression
+	if c, ok := s.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			exprs = append(exprs, where.Exprs...)
+		}
+	}
+	pk := s.Schema.PrioritizedPrimaryField
+	var ids []interface{}
+	eachModel(s.ReflectValue, func(v reflect.Value) {
+		if id, zero := pk.ValueOf(s.Context, v); !zero {
+			ids = append(ids, id)
+		}
+	})
+	if len(ids) > 0 {
+		exprs = append(exprs, clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Values: ids})
+	}
+	return exprs
+}
+
+// load 在当前连接（事务）中从主库查询记录，按主键排序，最多 maxRows+1 行；forUpdate 时锁定查到的行（SQLite 不支持，整库写锁已保证一致）
+func (a *auditor) load(tx *gorm.DB, exprs []clause.Expression, forUpdate bool) ([]*record, error) {
+	s := tx.Statement
+	dest := reflect.New(reflect.SliceOf(reflect.PointerTo(s.Schema.ModelType)))
+	q := tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).
+		Table(s.Table).
+		Clauses(dbresolver.Write).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: s.Schema.PrioritizedPrimaryField.DBName}}).
+		Limit(a.maxRows + 1)
+	if len(exprs) > 0 {
+		q = q.Clauses(clause.Where{Exprs: exprs})
+	}
+	if forUpdate && tx.Dialector.Name() != "sqlite" {
+		q = q.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate})
+	}
+	if err := q.Find(dest.Interface()).Error; err != nil {
+		return nil, err
+	}
+
+	list := dest.Elem()
+	records := make([]*record, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		records = append(records, a.record(s, reflect.Indirect(list.Index(i))))
+	}
+	return records, nil
+}
+
+// record 读取模型的主键及字段值，json:"-" 的字段（如密钥哈希）不记录
+func (a *auditor) record(s *gorm.Statement, v reflect.Value) *record {
+	values := make(map[string]interface{}, len(s.Schema.Fields))
+	for _, f := range s.Schema.Fields {
+		if f.DBName == "" || f.Tag.Get("json") == "-" {
+			continue
+		}
+		values[f.DBName] = jsonValue(f.ReflectValueOf(s.Context, v).Interface())
+	}
+	id, _ := s.Schema.PrioritizedPrimaryField.ValueOf(s.Context, v)
+	return &record{ID: id, Values: values}
+}
+
+func (a *auditor) write(tx *gorm.DB, logs []*Log) {
+	if len(logs) == 0 {
+		return
+	}
+	err := tx.Session(&gorm.Session{NewDB: true, SkipHooks: true, SkipDefaultTransaction: true}).Create(&logs).Error
+	if err != nil {
+		log.Logger.Error("write audit log error", zap.String("table", tx.Statement.Table), zap.Error(err))
+		_ = tx.AddError(err)
+	}
+}
+
+// newLog 创建审计日志，操作者及请求信息取自语句的 context
+func newLog(s *gorm.Statement, action string, id interface{}, before, after map[string]interface{}) *Log {
+	l := &Log{
+		Action:   action,
+		Entity:   s.Table,
+		EntityId: fmt.Sprint(id),
+		Before:   before,
+		After:    after,
+	}
+	l.ActorType, l.Actor = Actor(s.Context)
+	if r := RequestFromContext(s.Context); r != nil {
+		l.Method, l.Route, l.Ip, l.RequestId = r.Method, r.Route, r.Ip, r.RequestId
+	}
+	return l
+}
+
+// diff 变更的字段，自动更新时间字段不参与比较
+func diff(s *gorm.Statement, before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	old, changed := map[string]interface{}{}, map[string]interface{}{}
+	for name, v := range after {
+		if f := s.Schema.LookUpField(name); f != nil && f.AutoUpdateTime > 0 {
+			continue
+		}
+		if !reflect.DeepEqual(before[name], v) {
+			old[name], changed[name] = before[name], v
+		}
+	}
+	return old, changed
+}
+
+// updateAction 逻辑删除表 deleted 由 false 变为 true 时记为逻辑删除，由 true 变为 false 时记为恢复
+func updateAction(old, changed map[string]interface{}) string {
+	switch {
+	case changed["deleted"] == true && old["deleted"] != true:
+		return ActionSoftDelete
+	case changed["deleted"] == false && old["deleted"] == true:
+		return ActionRestore
+	}
+	return ActionUpdate
+}
+
+// jsonValue 转为 JSON 解码后的值（时间为字符串，数字为 float64），便于比较及存储
+func jsonValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var out interface{}
+	_ = json.Unmarshal(data, &out)
+	return out
+}
+
+// eachModel 遍历语句中的模型（单个结构体或切片）
+func eachModel(v reflect.Value, fn func(v reflect.Value)) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		fn(v)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if e := reflect.Indirect(v.Index(i)); e.Kind() == reflect.Struct {
+				fn(e)
+			}
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"bossfi-backend/src/core/cache"
+	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/ratelimit"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// apiKeyPrefix API Key 明文前缀，便于识别泄露的 key
+	apiKeyPrefix = "bfk_"
+	// apiKeyBytes API Key 随机部分字节数
+	apiKeyBytes = 32
+	// apiKeyCacheTTL 校验结果缓存时间，吊销时主动删除
+	apiKeyCacheTTL = time.Minute
+	// lastUsedInterval 最后使用时间的最小更新间隔，避免每次请求都写库
+	lastUsedInterval = time.Minute
+)
+
+var (
+	// ErrInvalidApiKey API Key 不存在、已吊销或已过期
+	ErrInvalidApiKey = errors.New("invalid api key")
+	// ErrInvalidScope 授权范围格式错误
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrScopeNotGranted 授权范围超出调用方自身的权限
+	ErrScopeNotGranted = errors.New("scope not granted to caller")
+)
+
+// scopePattern 授权范围格式 资源:操作，如 evm:read、demo:*，或 * 表示全部
+var scopePattern = regexp.MustCompile(`^(\*|[a-z][a-z0-9_]*:(\*|[a-z][a-z0-9_]*))$`)
+
+// lastUsed key id -> 上次写入最后使用时间
+var lastUsed sync.Map
+
+// ApiKey API Key，数据库只保存 key 的哈希
+type ApiKey struct {
+	ID           int64      `json:"id" gorm:"column:id;primaryKey"`
+	Name         string     `json:"name" gorm:"column:name"`
+	Owner        string     `json:"owner" gorm:"column:owner"`
+	Prefix       string     `json:"prefix" gorm:"column:prefix"`
+	KeyHash      string     `json:"-" gorm:"column:key_hash"`
+	Scopes       []string   `json:"scopes" gorm:"column:scopes;type:jsonb;serializer:json"`
+	RateLimit    int        `json:"rate_limit" gorm:"column:rate_limit"`
+	RatePeriod   int        `json:"rate_period" gorm:"column:rate_period"`
+	ExpireTime   *time.Time `json:"expire_time" gorm:"column:expire_time"`
+	LastUsedTime *time.Time `json:"last_used_time" gorm:"column:last_used_time"`
+	RevokeTime   *time.Time `json:"revoke_time" gorm:"column:revoke_time"`
+	CreateTime   time.Time  `json:"create_time" gorm:"column:create_time;autoCreateTime"`
+	ModifyTime   time.Time  `json:"modify_time" gorm:"column:modify_time;autoUpdateTime"`
+}
+
+func (ApiKey) TableName() string {
+	return "bossfi_api_key"
+}
+
+// Valid 是否可用：未吊销且未过期
+func (k *ApiKey) Valid(now time.Time) bool {
+	return k.RevokeTime == nil && (k.ExpireTime == nil || now.Before(*k.ExpireTime))
+}
+
+// Principal 转换为认证主体
+func (k *ApiKey) Principal() *Principal {
+	p := &Principal{
+		Type:    PrincipalApiKey,
+		Subject: strconv.FormatInt(k.ID, 10),
+		Name:    k.Owner,
+		Scopes:  k.Scopes,
+	}
+	if k.RateLimit > 0 && k.RatePeriod > 0 {
+		p.RateLimit = &ratelimit.Rule{Limit: k.RateLimit, Period: time.Duration(k.RatePeriod) * time.Second}
+	}
+	return p
+}
+
+// ApiKeyCreate 创建 API Key 参数
+type ApiKeyCreate struct {
+	Name       string     `json:"name" binding:"required"`  // 名称
+	Owner      string     `json:"owner" binding:"required"` // 所属方
+	Scopes     []string   `json:"scopes"`                   // 授权范围 如 ["evm:read","demo:write"]
+	ExpireTime *time.Time `json:"expire_time"`              // 过期时间，为空永不过期
+	RateLimit  int        `json:"rate_limit"`               // 限流次数，0 使用默认规则
+	RatePeriod int        `json:"rate_period"`              // 限流时间窗口(秒)
+}
+
+// ValidateScopes 校验授权范围格式
+func ValidateScopes(scopes []string) error {
+	for _, s := range scopes {
+		if !scopePattern.MatchString(s) {
+			return fmt.Errorf("%w: %q", ErrInvalidScope, s)
+		}
+	}
+	return nil
+}
+
+// ParseRate 解析限流规则 limit/period秒，如 1000/60
+func ParseRate(s string) (limit, period int, err error) {
+	l, p, ok := strings.Cut(s, "/")
+	if ok {
+		limit, err = strconv.Atoi(l)
+		if err == nil {
+			period, err = strconv.Atoi(p)
+		}
+	}
+	if !ok || err != nil || limit <= 0 || period <= 0 {
+		return 0, 0, fmt.Errorf("invalid rate %q, expected limit/seconds", s)
+	}
+	return limit, period, nil
+}
+
+// CreateApiKey 创建 API Key，返回记录及明文 key，明文只在创建时返回一次
+//
+// ctx 中存在认证主体（通过接口创建）时，授权范围不能超出其有效权限，否则返回 ErrScopeNotGranted；
+// 命令行创建时没有认证主体，不受限制
+func CreateApiKey(ctx context.Context, req *ApiKeyCreate) (*ApiKey, string, error) {
+	if err := ValidateScopes(req.Scopes); err != nil {
+		return nil, "", err
+	}
+	if caller := FromContext(ctx); caller != nil {
+		granted, err := Permissions(ctx, caller)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, s := range req.Scopes {
+			if !matchScope(granted, s) {
+				return nil, "", fmt.Errorf("%w: %q", ErrScopeNotGranted, s)
+			}
+		}
+	}
+	if req.RateLimit < 0 || req.RatePeriod < 0 || (req.RateLimit > 0) != (req.RatePeriod > 0) {
+		return nil, "", fmt.Errorf("invalid rate limit %d/%d", req.RateLimit, req.RatePeriod)
+	}
+
+	buf := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	plain := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	scopes := req.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	key := &ApiKey{
+		Name:       req.Name,
+		Owner:      req.Owner,
+		Prefix:     plain[:len(apiKeyPrefix)+6],
+		KeyHash:    hashApiKey(plain),
+		Scopes:     scopes,
+		RateLimit:  req.RateLimit,
+		RatePeriod: req.RatePeriod,
+		ExpireTime: req.ExpireTime,
+	}
+	if err := db.FromContext(ctx).Create(key).Error; err != nil {
+		return nil, "", err
+	}
+	return key, plain, nil
+}
+
+// VerifyApiKey 校验 API Key，返回对应记录；不存在、已吊销或已过期返回 ErrInvalidApiKey
+func VerifyApiKey(ctx context.Context, plain string) (*ApiKey, error) {
+	if !strings.HasPrefix(plain, apiKeyPrefix) {
+		return nil, ErrInvalidApiKey
+	}
+	hash := hashApiKey(plain)
+	load := func(ctx context.Context) (*ApiKey, error) {
+		var key ApiKey
+		err := db.FromContext(ctx).Where("key_hash = ?", hash).First(&key).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidApiKey
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &key, nil
+	}
+
+	var key *ApiKey
+	var err error
+	if cache.Default != nil {
+		key, err = cache.GetOrLoad(ctx, cache.Default, apiKeyCacheKey(hash), apiKeyCacheTTL, load)
+	} else {
+		key, err = load(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.Valid(now) {
+		return nil, ErrInvalidApiKey
+	}
+	touchApiKey(key.ID, now)
+	return key, nil
+}
+
+// touchApiKey 异步更新最后使用时间，同一 key 每分钟最多写一次
+func touchApiKey(id int64, now time.Time) {
+	if last, ok := lastUsed.Load(id); ok && now.Sub(last.(time.Time)) < lastUsedInterval {
+		return
+	}
+	lastUsed.Store(id, now)
+	go func() {
+		err := db.DB.Model(&ApiKey{}).Where("id = ?", id).UpdateColumn("last_used_time", now).Error
+		if err != nil {
+			log.Logger.Warn("update api key last used time error", zap.Int64("id", id), zap.Error(err))
+		}
+	}()
+}
+
+// ListApiKeys 查询 API Key 列表，owner 为空时查询全部
+func ListApiKeys(ctx context.Context, owner string) ([]*ApiKey, error) {
+	tx := db.FromContext(ctx).Order("id desc")
+	if owner != "" {
+		tx = tx.Where("owner = ?", owner)
+	}
+	var list []*ApiKey
+	if err := tx.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// RevokeApiKey 吊销 API Key，已吊销或不存在返回 gorm.ErrRecordNotFound
+func RevokeApiKey(ctx context.Context, id int64) error {
+	var key ApiKey
+	if err := db.FromContext(ctx).Where("id = ? and revoke_time is null", id).First(&key).Error; err != nil {
+		return err
+	}
+	err := db.FromContext(ctx).Model(&key).Update("revoke_time", time.Now()).Error
+	if err != nil {
+		return err
+	}
+	// 删除校验缓存，其他实例的一级缓存在 local_ttl 后过期
+	if cache.Default != nil {
+		if err := cache.Default.Delete(ctx, apiKeyCacheKey(key.KeyHash)); err != nil {
+			log.Logger.Warn("delete api key cache error", zap.Int64("id", id), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func hashApiKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyCacheKey(hash string) string {
+	return cache.Key("apikey", hash)
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"bossfi-backend/src/core/ratelimit"
+	"context"
+	"strings"
+)
+
+// 认证主体类型
+const (
+	PrincipalApiKey = "api_key"
+	PrincipalWallet = "wallet"
+)
+
+// ScopeAll 全部权限
+const ScopeAll = "*"
+
+// Principal 已认证的调用方，由认证中间件写入请求 context
+type Principal struct {
+	Type      string          // 主体类型 api_key/wallet
+	Subject   string          // 主体标识：API Key 为 key id，钱包为地址
+	Name      string          // 名称：API Key 为所属方
+	Scopes    []string        // 授权范围
+	RateLimit *ratelimit.Rule // 单独的限流规则，为空时使用默认规则
+}
+
+// HasScope 是否拥有授权范围：* 拥有全部权限，evm:* 拥有 evm 下全部权限
+func (p *Principal) HasScope(scope string) bool {
+	return matchScope(p.Scopes, scope)
+}
+
+// matchScope granted 中是否包含 scope，支持 * 及 资源:* 通配
+func matchScope(granted []string, scope string) bool {
+	for _, s := range granted {
+		if s == ScopeAll || s == scope {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(s, ":*"); ok && strings.HasPrefix(scope, prefix+":") {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal 将认证主体写入 context
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext 获取 context 中的认证主体，未认证返回 nil
+func FromContext(ctx context.Context) *Principal {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
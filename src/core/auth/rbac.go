@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"bossfi-backend/src/core/cache"
+	"bossfi-backend/src/core/db"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm/clause"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RoleAdmin 内置管理员角色，拥有全部权限
+const RoleAdmin = "admin"
+
+const (
+	// roleCacheTTL 角色绑定缓存时间，绑定变更时主动失效
+	roleCacheTTL = time.Minute
+	// roleCacheTag 角色绑定缓存标签
+	roleCacheTag = "rbac"
+)
+
+// ErrUnknownRole 角色未声明
+var ErrUnknownRole = errors.New("unknown role")
+
+// roles 角色 -> 权限，在 init 中通过 DefineRole 声明
+var roles = map[string][]string{
+	RoleAdmin: {ScopeAll},
+}
+
+// DefineRole 声明角色及其权限，权限格式同 API Key 授权范围，重复声明覆盖；只应在 init 中调用
+func DefineRole(name string, permissions ...string) {
+	if err := ValidateScopes(permissions); err != nil {
+		panic(fmt.Sprintf("define role %s: %v", name, err))
+	}
+	roles[name] = permissions
+}
+
+// RoleDefined 角色是否已声明
+func RoleDefined(name string) bool {
+	_, ok := roles[name]
+	return ok
+}
+
+// RolePermissions 角色的权限
+func RolePermissions(name string) []string {
+	return roles[name]
+}
+
+// RoleBinding 角色绑定：将角色授予钱包地址或 API Key
+type RoleBinding struct {
+	ID          int64     `json:"id" gorm:"column:id;primaryKey"`
+	SubjectType string    `json:"subject_type" gorm:"column:subject_type"`
+	Subject     string    `json:"subject" gorm:"column:subject"`
+	Role        string    `json:"role" gorm:"column:role"`
+	CreateTime  time.Time `json:"create_time" gorm:"column:create_time;autoCreateTime"`
+	ModifyTime  time.Time `json:"modify_time" gorm:"column:modify_time;autoUpdateTime"`
+}
+
+func (RoleBinding) TableName() string {
+	return "bossfi_role_binding"
+}
+
+// Roles 查询认证主体绑定的角色，结果缓存 1 分钟；未声明的角色忽略
+func Roles(ctx context.Context, p *Principal) ([]string, error) {
+	subjectType, subject := p.Type, normalizeSubject(p.Type, p.Subject)
+	load := func(ctx context.Context) ([]string, error) {
+		var names []string
+		err := db.FromContext(ctx).Model(&RoleBinding{}).
+			Where("subject_type = ? and subject = ?", subjectType, subject).
+			Order("role").Pluck("role", &names).Error
+		if names == nil {
+			names = []string{}
+		}
+		return names, err
+	}
+
+	var names []string
+	var err error
+	if cache.Default != nil {
+		key := cache.Key("rbac", subjectType, subject)
+		names, err = cache.GetOrLoad(ctx, cache.Default, key, roleCacheTTL, load, roleCacheTag)
+	} else {
+		names, err = load(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defined := make([]string, 0, len(names))
+	for _, name := range names {
+		if RoleDefined(name) {
+			defined = append(defined, name)
+		}
+	}
+	return defined, nil
+}
+
+// Permissions 认证主体的有效权限：API Key 授权范围与所绑定角色权限的并集
+func Permissions(ctx context.Context, p *Principal) ([]string, error) {
+	names, err := Roles(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	set := map[string]bool{}
+	for _, s := range p.Scopes {
+		set[s] = true
+	}
+	for _, name := range names {
+		for _, s := range roles[name] {
+			set[s] = true
+		}
+	}
+	list := make([]string, 0, len(set))
+	for s := range set {
+		list = append(list, s)
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// Authorize 认证主体是否拥有全部权限，授权范围已满足时不查询角色
+func Authorize(ctx context.Context, p *Principal, permissions ...string) (bool, error) {
+	missing := false
+	for _, perm := range permissions {
+		if !p.HasScope(perm) {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return true, nil
+	}
+
+	granted, err := Permissions(ctx, p)
+	if err != nil {
+		return false, err
+	}
+	for _, perm := range permissions {
+		if !matchScope(granted, perm) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// HasRole 认证主体是否拥有任一角色：绑定了该角色，或有效权限已覆盖该角色的全部权限
+// （如授权范围为 * 的 API Key 视为拥有 admin），与 Authorize 的判断保持一致
+func HasRole(ctx context.Context, p *Principal, names ...string) (bool, error) {
+	bound, err := Roles(ctx, p)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range names {
+		for _, b := range bound {
+			if b == name {
+				return true, nil
+			}
+		}
+	}
+
+	granted, err := Permissions(ctx, p)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range names {
+		if covers(granted, roles[name]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// covers granted 是否包含全部 permissions，permissions 为空时返回 false
+func covers(granted, permissions []string) bool {
+	if len(permissions) == 0 {
+		return false
+	}
+	for _, perm := range permissions {
+		if !matchScope(granted, perm) {
+			return false
+		}
+	}
+	return true
+}
+
+// GrantRole 授予角色，已授予时忽略
+func GrantRole(ctx context.Context, subjectType, subject, role string) error {
+	if err := checkBinding(subjectType, role); err != nil {
+		return err
+	}
+	binding := &RoleBinding{SubjectType: subjectType, Subject: normalizeSubject(subjectType, subject), Role: role}
+	err := db.FromContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(binding).Error
+	if err != nil {
+		return err
+	}
+	return invalidateRoles(ctx)
+}
+
+// RevokeRole 收回角色
+func RevokeRole(ctx context.Context, subjectType, subject, role string) error {
+	err := db.FromContext(ctx).
+		Where("subject_type = ? and subject = ? and role = ?", subjectType, normalizeSubject(subjectType, subject), role).
+		Delete(&RoleBinding{}).Error
+	if err != nil {
+		return err
+	}
+	return invalidateRoles(ctx)
+}
+
+// ListRoleBindings 查询角色绑定，subjectType/subject 为空时不过滤
+func ListRoleBindings(ctx context.Context, subjectType, subject string) ([]*RoleBinding, error) {
+	tx := db.FromContext(ctx).Order("id")
+	if subjectType != "" {
+		tx = tx.Where("subject_type = ?", subjectType)
+	}
+	if subject != "" {
+		tx = tx.Where("subject = ?", normalizeSubject(subjectType, subject))
+	}
+	var list []*RoleBinding
+	if err := tx.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func checkBinding(subjectType, role string) error {
+	if subjectType != PrincipalWallet && subjectType != PrincipalApiKey {
+		return fmt.Errorf("invalid subject type %q", subjectType)
+	}
+	if !RoleDefined(role) {
+		return fmt.Errorf("%w: %q", ErrUnknownRole, role)
+	}
+	return nil
+}
+
+// normalizeSubject 钱包地址统一小写
+func normalizeSubject(subjectType, subject string) string {
+	if subjectType == PrincipalWallet {
+		return strings.ToLower(subject)
+	}
+	return subject
+}
+
+func invalidateRoles(ctx context.Context) error {
+	if cache.Default == nil {
+		return nil
+	}
+	return cache.Default.InvalidateTags(ctx, roleCacheTag)
+}
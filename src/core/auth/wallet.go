@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/db"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gomodule/redigo/redis"
+	"strings"
+	"time"
+)
+
+const (
+	// walletNonceTTL 登录消息有效期，签名需在此时间内提交
+	walletNonceTTL = 5 * time.Minute
+	// sessionPrefix 会话 token 明文前缀
+	sessionPrefix = "bfs_"
+	// sessionBytes 会话 token 随机部分字节数
+	sessionBytes = 32
+	// SessionTTL 钱包登录会话有效期
+	SessionTTL = 24 * time.Hour
+)
+
+var (
+	// ErrInvalidAddress 钱包地址格式错误
+	ErrInvalidAddress = errors.New("invalid wallet address")
+	// ErrInvalidSignature 签名错误，或登录消息不存在、已过期、已使用
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrInvalidSession 会话不存在或已过期
+	ErrInvalidSession = errors.New("invalid session")
+)
+
+// consumeScript 值未变时删除（compare-and-delete），并发使用同一登录消息时只有一个成功
+var consumeScript = redis.NewScript(1, `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// WalletNonce 生成钱包登录消息，返回 nonce 及待签名的消息，客户端使用钱包对消息签名（personal_sign）后
+// 携带 nonce 调用 WalletLogin；消息按 nonce 保存，5 分钟内有效，同一地址的多个消息互不影响
+func WalletNonce(ctx context.Context, address string) (string, string, error) {
+	if !common.IsHexAddress(address) {
+		return "", "", ErrInvalidAddress
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	nonce := hex.EncodeToString(buf)
+	message := fmt.Sprintf("%s wants you to sign in with your Ethereum account:\n%s\n\nNonce: %s\nIssued At: %s",
+		config.Conf.App.Name, common.HexToAddress(address).Hex(), nonce, time.Now().UTC().Format(time.RFC3339))
+
+	conn, err := db.Redis.GetContext(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	defer conn.Close()
+	// 值为 地址\n消息，登录时校验消息是为该地址生成的
+	value := strings.ToLower(address) + "\n" + message
+	_, err = redis.DoContext(conn, ctx, "SET", walletRedisKey("nonce", nonce), value, "PX", walletNonceTTL.Milliseconds())
+	return nonce, message, err
+}
+
+// WalletLogin 校验登录消息的签名，成功后创建会话并返回会话 token；
+// 签名校验通过后才删除登录消息，错误的签名不会使他人的登录消息失效，登录消息只能使用一次
+func WalletLogin(ctx context.Context, address, nonce, signature string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", ErrInvalidAddress
+	}
+	addr := strings.ToLower(address)
+
+	conn, err := db.Redis.GetContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	nonceKey := walletRedisKey("nonce", nonce)
+	value, err := redis.String(redis.DoContext(conn, ctx, "GET", nonceKey))
+	if errors.Is(err, redis.ErrNil) {
+		return "", ErrInvalidSignature
+	}
+	if err != nil {
+		return "", err
+	}
+	owner, message, _ := strings.Cut(value, "\n")
+	if owner != addr || !verifySignature(message, signature, common.HexToAddress(address)) {
+		return "", ErrInvalidSignature
+	}
+	deleted, err := redis.Int(consumeScript.DoContext(ctx, conn, nonceKey, value))
+	if err != nil {
+		return "", err
+	}
+	if deleted == 0 {
+		return "", ErrInvalidSignature
+	}
+
+	buf := make([]byte, sessionBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := sessionPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	_, err = redis.DoContext(conn, ctx, "SET", walletRedisKey("session", hashSession(token)), addr, "PX", SessionTTL.Milliseconds())
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// VerifySession 校验会话 token，返回钱包地址认证主体
+func VerifySession(ctx context.Context, token string) (*Principal, error) {
+	if !strings.HasPrefix(token, sessionPrefix) {
+		return nil, ErrInvalidSession
+	}
+	conn, err := db.Redis.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	addr, err := redis.String(redis.DoContext(conn, ctx, "GET", walletRedisKey("session", hashSession(token))))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, ErrInvalidSession
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{Type: PrincipalWallet, Subject: addr, Name: addr}, nil
+}
+
+// Logout 删除会话
+func Logout(ctx context.Context, token string) error {
+	conn, err := db.Redis.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = redis.DoContext(conn, ctx, "DEL", walletRedisKey("session", hashSession(token)))
+	return err
+}
+
+// verifySignature 校验 personal_sign 签名是否由 address 签出，v 兼容 0/1 及 27/28
+func verifySignature(message, signature string, address common.Address) bool {
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return false
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return false
+	}
+	return crypto.PubkeyToAddress(*pub) == address
+}
+
+// hashSession Redis 只保存会话 token 的哈希
+func hashSession(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func walletRedisKey(kind, id string) string {
+	return config.Conf.App.Name + ":auth:" + kind + ":" + id
+}
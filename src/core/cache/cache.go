@@ -0,0 +1,327 @@
+package cache
+
+import (
+	"bossfi-backend/src/core/db"
+	"context"
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"golang.org/x/sync/singleflight"
+	"strings"
+	"time"
+)
+
+// ErrMiss 缓存未命中
+var ErrMiss = errors.New("cache miss")
+
+// Default 默认缓存实例，InitCache 后可用
+var Default *Cache
+
+// Pool Redis连接池
+type Pool interface {
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
+// tagScript 将缓存key加入标签集合，并保证标签集合的过期时间不短于该key
+// KEYS[1] 标签集合 ARGV[1] 缓存key ARGV[2] 过期时间(毫秒)，0 表示永不过期
+//
+// 已有永不过期成员的集合（PTTL 为 -1）保持永不过期，否则其中的永久key无法通过标签失效
+var tagScript = redis.NewScript(1, `
+local existed = redis.call('EXISTS', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call('PERSIST', KEYS[1])
+	return 1
+end
+if existed == 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	return 1
+end
+local pttl = redis.call('PTTL', KEYS[1])
+if pttl >= 0 and pttl < ttl then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// defaultLoadTimeout 合并加载的默认超时时间
+const defaultLoadTimeout = 10 * time.Second
+
+// Cache Redis缓存，可选进程内LRU作为一级缓存
+//
+// 一级缓存仅在本进程内失效，其他实例的一级缓存在 localTTL 后过期，因此 localTTL 应设置较短
+type Cache struct {
+	pool      Pool
+	namespace string
+	codec     Codec
+	local     *lru
+	localTTL  time.Duration
+	group     singleflight.Group
+	// loadTimeout 合并加载的超时时间，加载不受单个调用方 context 取消的影响
+	loadTimeout time.Duration
+}
+
+// Option 缓存选项
+type Option func(*Cache)
+
+// WithNamespace 设置key命名空间，一般为应用名
+func WithNamespace(namespace string) Option {
+	return func(c *Cache) {
+		c.namespace = namespace
+	}
+}
+
+// WithCodec 设置编解码，默认 JSON
+func WithCodec(codec Codec) Option {
+	return func(c *Cache) {
+		c.codec = codec
+	}
+}
+
+// WithLocal 启用进程内LRU一级缓存
+func WithLocal(size int, ttl time.Duration) Option {
+	return func(c *Cache) {
+		if size > 0 && ttl > 0 {
+			c.local = newLRU(size)
+			c.localTTL = ttl
+		}
+	}
+}
+
+// WithLoadTimeout 设置 GetOrLoad 合并加载的超时时间，默认 10s
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(c *Cache) {
+		if timeout > 0 {
+			c.loadTimeout = timeout
+		}
+	}
+}
+
+// New 创建缓存
+func New(pool Pool, opts ...Option) *Cache {
+	c := &Cache{pool: pool, codec: JSON, loadTimeout: defaultLoadTimeout}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Key 拼接业务key 如 Key("block", "1", "100") => block:1:100
+func Key(parts ...string) string {
+	return strings.Join(parts, ":")
+}
+
+// redisKey 加上命名空间的完整key
+func (c *Cache) redisKey(key string) string {
+	if c.namespace == "" {
+		return key
+	}
+	return c.namespace + ":" + key
+}
+
+func (c *Cache) tagKey(tag string) string {
+	return c.redisKey("tag:" + tag)
+}
+
+// GetBytes 读取原始缓存值，未命中返回 ErrMiss
+func (c *Cache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	rk := c.redisKey(key)
+	if c.local != nil {
+		if data, ok := c.local.get(rk); ok {
+			return data, nil
+		}
+	}
+
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// 同时取剩余过期时间，避免一级缓存比Redis存活更久
+	if err := conn.Send("GET", rk); err != nil {
+		return nil, err
+	}
+	if err := conn.Send("PTTL", rk); err != nil {
+		return nil, err
+	}
+	if err := conn.Flush(); err != nil {
+		return nil, err
+	}
+	data, err := redis.Bytes(conn.Receive())
+	if errors.Is(err, redis.ErrNil) {
+		_, _ = conn.Receive()
+		return nil, ErrMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	pttl, err := redis.Int64(conn.Receive())
+	if err != nil {
+		return nil, err
+	}
+
+	if c.local != nil {
+		ttl := c.localTTL
+		if pttl > 0 && time.Duration(pttl)*time.Millisecond < ttl {
+			ttl = time.Duration(pttl) * time.Millisecond
+		}
+		c.local.set(rk, data, ttl)
+	}
+	return data, nil
+}
+
+// SetBytes 写入原始缓存值，ttl <= 0 表示永不过期，tags 用于按标签批量失效
+func (c *Cache) SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration, tags ...string) error {
+	rk := c.redisKey(key)
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if ttl > 0 {
+		_, err = conn.Do("SET", rk, data, "PX", ttl.Milliseconds())
+	} else {
+		_, err = conn.Do("SET", rk, data)
+	}
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tagScript.Do(conn, c.tagKey(tag), rk, ttl.Milliseconds()); err != nil {
+			return err
+		}
+	}
+
+	if c.local != nil {
+		localTTL := c.localTTL
+		if ttl > 0 && ttl < localTTL {
+			localTTL = ttl
+		}
+		c.local.set(rk, data, localTTL)
+	}
+	return nil
+}
+
+// Delete 删除缓存
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	rks := make([]string, 0, len(keys))
+	for _, key := range keys {
+		rks = append(rks, c.redisKey(key))
+	}
+	return c.deleteRedisKeys(ctx, rks)
+}
+
+// InvalidateTags 删除标签下的全部缓存
+func (c *Cache) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tk := c.tagKey(tag)
+		members, err := c.tagMembers(ctx, tk)
+		if err != nil {
+			return err
+		}
+		if err := c.deleteRedisKeys(ctx, append(members, tk)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) tagMembers(ctx context.Context, tk string) ([]string, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return redis.Strings(conn.Do("SMEMBERS", tk))
+}
+
+// deleteRedisKeys 逐个删除key（兼容集群模式下key分布在不同slot）
+func (c *Cache) deleteRedisKeys(ctx context.Context, rks []string) error {
+	if c.local != nil {
+		c.local.delete(rks...)
+	}
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, rk := range rks {
+		if err := conn.Send("DEL", rk); err != nil {
+			return err
+		}
+	}
+	_, err = conn.Do("")
+	return err
+}
+
+// Get 读取并解码缓存值，未命中返回 ErrMiss
+func Get[T any](ctx context.Context, c *Cache, key string) (T, error) {
+	var v T
+	data, err := c.GetBytes(ctx, key)
+	if err != nil {
+		return v, err
+	}
+	err = c.codec.Unmarshal(data, &v)
+	return v, err
+}
+
+// Set 编码并写入缓存值
+func Set[T any](ctx context.Context, c *Cache, key string, v T, ttl time.Duration, tags ...string) error {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.SetBytes(ctx, key, data, ttl, tags...)
+}
+
+// GetOrLoad 旁路缓存：命中直接返回，未命中时调用 load 加载并写入缓存；
+// 同一进程内相同key的并发加载合并为一次，防止缓存击穿
+//
+// 缓存读写失败时降级为直接调用 load，不影响业务
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	return GetOrLoadTTL(ctx, c, key, func(ctx context.Context) (T, time.Duration, []string, error) {
+		v, err := load(ctx)
+		return v, ttl, tags, err
+	})
+}
+
+// GetOrLoadTTL 同 GetOrLoad，过期时间和标签由 load 根据加载结果决定，返回的 ttl < 0 时不写入缓存
+//
+// 合并的加载由多个调用方共享：load 使用不随调用方取消的 context（超时为 WithLoadTimeout）且不加入调用方的事务，
+// 调用方 ctx 结束时该调用方直接返回 ctx.Err()，不影响其他等待者
+func GetOrLoadTTL[T any](ctx context.Context, c *Cache, key string, load func(ctx context.Context) (T, time.Duration, []string, error)) (T, error) {
+	var zero T
+	if v, err := Get[T](ctx, c, key); err == nil {
+		return v, nil
+	}
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(db.WithoutTx(context.WithoutCancel(ctx)), c.loadTimeout)
+		defer cancel()
+		// 等待期间其他调用可能已写入缓存
+		if v, err := Get[T](loadCtx, c, key); err == nil {
+			return v, nil
+		}
+		v, ttl, tags, err := load(loadCtx)
+		if err != nil {
+			return v, err
+		}
+		if ttl >= 0 {
+			_ = Set(loadCtx, c, key, v, ttl, tags...)
+		}
+		return v, nil
+	})
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		v, _ := res.Val.(T)
+		return v, res.Err
+	}
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"encoding/json"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec 缓存值编解码
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSON JSON编解码，可读性好，默认使用
+var JSON Codec = jsonCodec{}
+
+// Msgpack msgpack编解码，体积更小、速度更快
+var Msgpack Codec = msgpackCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// CodecByName 按名称获取编解码 json/msgpack，未知名称返回 JSON
+func CodecByName(name string) Codec {
+	if name == "msgpack" {
+		return Msgpack
+	}
+	return JSON
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru 进程内LRU缓存，支持单条过期时间
+type lru struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+func (l *lru) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expireAt) {
+		l.removeElement(el)
+		return nil, false
+	}
+	l.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (l *lru) set(key string, value []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	expireAt := time.Now().Add(ttl)
+	if el, ok := l.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value, entry.expireAt = value, expireAt
+		l.ll.MoveToFront(el)
+		return
+	}
+	l.items[key] = l.ll.PushFront(&lruEntry{key: key, value: value, expireAt: expireAt})
+	for l.ll.Len() > l.size {
+		l.removeElement(l.ll.Back())
+	}
+}
+
+func (l *lru) delete(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := l.items[key]; ok {
+			l.removeElement(el)
+		}
+	}
+}
+
+func (l *lru) removeElement(el *list.Element) {
+	l.ll.Remove(el)
+	delete(l.items, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	type op struct {
+		action string // set/get/delete
+		key    string
+		value  string
+		ttl    time.Duration
+		found  bool // get 期望是否命中
+	}
+	tests := []struct {
+		name string
+		size int
+		ops  []op
+	}{
+		{"get after set", 2, []op{
+			{action: "set", key: "a", value: "1", ttl: time.Minute},
+			{action: "get", key: "a", value: "1", found: true},
+			{action: "get", key: "b"},
+		}},
+		{"evict least recently used", 2, []op{
+			{action: "set", key: "a", value: "1", ttl: time.Minute},
+			{action: "set", key: "b", value: "2", ttl: time.Minute},
+			{action: "get", key: "a", value: "1", found: true},
+			{action: "set", key: "c", value: "3", ttl: time.Minute},
+			{action: "get", key: "b"},
+			{action: "get", key: "a", value: "1", found: true},
+			{action: "get", key: "c", value: "3", found: true},
+		}},
+		{"overwrite refreshes value", 1, []op{
+			{action: "set", key: "a", value: "1", ttl: time.Minute},
+			{action: "set", key: "a", value: "2", ttl: time.Minute},
+			{action: "get", key: "a", value: "2", found: true},
+		}},
+		{"expired", 2, []op{
+			{action: "set", key: "a", value: "1", ttl: -time.Second},
+			{action: "get", key: "a"},
+		}},
+		{"delete", 2, []op{
+			{action: "set", key: "a", value: "1", ttl: time.Minute},
+			{action: "set", key: "b", value: "2", ttl: time.Minute},
+			{action: "delete", key: "a"},
+			{action: "get", key: "a"},
+			{action: "get", key: "b", value: "2", found: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLRU(tt.size)
+			for i, o := range tt.ops {
+				switch o.action {
+				case "set":
+					l.set(o.key, []byte(o.value), o.ttl)
+				case "delete":
+					l.delete(o.key)
+				case "get":
+					v, ok := l.get(o.key)
+					if ok != o.found || string(v) != o.value {
+						t.Errorf("op %d get(%s) = %q, %v, want %q, %v", i, o.key, v, ok, o.value, o.found)
+					}
+				}
+			}
+			if l.ll.Len() != len(l.items) || l.ll.Len() > tt.size {
+				t.Errorf("list len %d, items %d, size %d", l.ll.Len(), len(l.items), tt.size)
+			}
+		})
+	}
+}
+
+func TestCodec(t *testing.T) {
+	type value struct {
+		Name  string
+		Count int64
+		Tags  []string
+	}
+	in := value{Name: "a", Count: 1 << 60, Tags: []string{"x", "y"}}
+	for _, name := range []string{"json", "msgpack", "unknown"} {
+		t.Run(name, func(t *testing.T) {
+			codec := CodecByName(name)
+			data, err := codec.Marshal(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out value
+			if err := codec.Unmarshal(data, &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != in.Name || out.Count != in.Count || len(out.Tags) != 2 || out.Tags[1] != "y" {
+				t.Errorf("round trip = %+v, want %+v", out, in)
+			}
+		})
+	}
+	if CodecByName("unknown") != JSON {
+		t.Error("unknown codec name should fall back to JSON")
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+// 链上事件 topic
+const (
+	TopicBlockIndexed  = "block_indexed"
+	TopicContractEvent = "contract_event"
+)
+
+// BlockIndexed 区块已索引事件，由索引器在区块处理完成后发布
+type BlockIndexed struct {
+
+	// 链ID
+	ChainId int `json:"chainId"`
+
+	// 区块高度
+	Number uint64 `json:"number"`
+
+	// 区块哈希
+	Hash string `json:"hash"`
+
+	// 父区块哈希
+	ParentHash string `json:"parentHash"`
+
+	// 区块时间
+	Time uint64 `json:"time"`
+
+	// 是否已终局
+	Finalized bool `json:"finalized"`
+}
+
+func (BlockIndexed) Topic() string {
+	return TopicBlockIndexed
+}
+
+// ContractEvent 合约事件，由索引器解析日志后发布
+type ContractEvent struct {
+
+	// 链ID
+	ChainId int `json:"chainId"`
+
+	// 区块高度
+	BlockNumber uint64 `json:"blockNumber"`
+
+	// 区块哈希
+	BlockHash string `json:"blockHash"`
+
+	// 交易哈希
+	TxHash string `json:"txHash"`
+
+	// 日志在区块中的序号
+	LogIndex uint `json:"logIndex"`
+
+	// 合约地址
+	Address string `json:"address"`
+
+	// 事件主题，第一个为事件签名哈希
+	Topics []string `json:"topics"`
+
+	// 事件数据
+	Data []byte `json:"data"`
+
+	// 是否因重组被移除
+	Removed bool `json:"removed"`
+}
+
+func (ContractEvent) Topic() string {
+	return TopicContractEvent
+}
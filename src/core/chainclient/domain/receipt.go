@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Receipt 交易收据
+type Receipt struct {
+
+	// 交易哈希值
+	TxHash string `json:"transactionHash"`
+
+	// 交易执行状态 1 成功 0 失败
+	Status uint64 `json:"status"`
+
+	// 所在区块哈希
+	BlockHash string `json:"blockHash"`
+
+	// 所在区块高度
+	BlockNumber uint64 `json:"blockNumber"`
+
+	// 交易在区块中的序号
+	TransactionIndex uint `json:"transactionIndex"`
+
+	// 交易gas使用量
+	GasUsed string `json:"gasUsed"`
+
+	// 区块内累计gas使用量
+	CumulativeGasUsed string `json:"cumulativeGasUsed"`
+
+	// 实际gas价格
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+
+	// 创建的合约地址（仅合约创建交易）
+	ContractAddress string `json:"contractAddress,omitempty"`
+
+	// 事件日志
+	Logs []*Log `json:"logs"`
+}
+
+// Log 合约事件日志
+type Log struct {
+	// 合约地址
+	Address string `json:"address"`
+
+	// 事件主题
+	Topics []string `json:"topics"`
+
+	// 事件数据
+	Data []byte `json:"data"`
+
+	// 日志在区块中的序号
+	Index uint `json:"logIndex"`
+}
+
+func ToReceipt(receipt *types.Receipt) *Receipt {
+	logs := make([]*Log, 0, len(receipt.Logs))
+	for _, l := range receipt.Logs {
+		topics := make([]string, 0, len(l.Topics))
+		for _, topic := range l.Topics {
+			topics = append(topics, topic.Hex())
+		}
+		logs = append(logs, &Log{
+			Address: l.Address.Hex(),
+			Topics:  topics,
+			Data:    l.Data,
+			Index:   l.Index,
+		})
+	}
+
+	var contractAddress string
+	if receipt.ContractAddress != (types.Receipt{}).ContractAddress {
+		contractAddress = receipt.ContractAddress.Hex()
+	}
+	var effectiveGasPrice string
+	if receipt.EffectiveGasPrice != nil {
+		effectiveGasPrice = "0x" + receipt.EffectiveGasPrice.Text(16)
+	}
+
+	return &Receipt{
+		TxHash:            receipt.TxHash.Hex(),
+		Status:            receipt.Status,
+		BlockHash:         receipt.BlockHash.Hex(),
+		BlockNumber:       receipt.BlockNumber.Uint64(),
+		TransactionIndex:  receipt.TransactionIndex,
+		GasUsed:           fmt.Sprintf("0x%x", receipt.GasUsed),
+		CumulativeGasUsed: fmt.Sprintf("0x%x", receipt.CumulativeGasUsed),
+		EffectiveGasPrice: effectiveGasPrice,
+		ContractAddress:   contractAddress,
+		Logs:              logs,
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"bossfi-backend/src/core/result"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Run 解析命令行子命令，无子命令时启动服务
+func Run(configFile string, args []string) {
+	if len(args) == 0 {
+		Start(configFile)
+		return
+	}
+
+	var err error
+	switch args[0] {
+	case "serve":
+		Start(configFile)
+	case "codes":
+		err = runCodes(args[1:])
+	case "migrate":
+		err = runMigrate(configFile, args[1:])
+	case "apikey":
+		err = runApiKey(configFile, args[1:])
+	case "role":
+		err = runRole(configFile, args[1:])
+	default:
+		err = fmt.Errorf("unknown command %q, available: serve, codes, migrate, apikey, role", args[0])
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runCodes 导出业务状态码表 用法: codes [-format json|md] [-o file]
+func runCodes(args []string) error {
+	fs := flag.NewFlagSet("codes", flag.ContinueOnError)
+	format := fs.String("format", "json", "output format: json or md")
+	output := fs.String("o", "", "output file, default stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := result.Check(); err != nil {
+		return err
+	}
+
+	var data []byte
+	switch *format {
+	case "json":
+		b, err := result.ExportJSON()
+		if err != nil {
+			return err
+		}
+		data = append(b, '\n')
+	case "md", "markdown":
+		data = []byte(result.ExportMarkdown())
+	default:
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	if *output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0644)
+}
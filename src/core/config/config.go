@@ -9,17 +9,34 @@ import (
 var Conf *Config
 
 type Config struct {
-	App     AppConfig
-	Monitor MonitorConfig
-	Pgsql   PgsqlConfig
-	Redis   RedisConfig
-	Chains  []ChainConfig
+	App         AppConfig
+	Monitor     MonitorConfig
+	DataSource  DataSourceConfig
+	Pgsql       PgsqlConfig
+	Mysql       MysqlConfig
+	Sqlite      SqliteConfig
+	Redis       RedisConfig
+	Cache       CacheConfig
+	RateLimit   RateLimitConfig `toml:"rate_limit"`
+	Timeout     TimeoutConfig
+	Idempotency IdempotencyConfig
+	Report      ReportConfig
+	Compress    CompressConfig
+	Audit       AuditConfig
+	Retention   RetentionConfig
+	MQ          MQConfig
+	Cors        CorsConfig
+	Chains      []ChainConfig
 }
 
 type AppConfig struct {
 	Name    string `toml:"name" json:"name"`
 	Port    string `toml:"port" json:"port"`
 	Version string `toml:"version" json:"version"`
+	Env     string `toml:"env" json:"env"`  // 运行环境 dev/test/prod，用于选择种子数据等
+	Secret  string `toml:"secret" json:"-"` // 签名密钥，用于分页游标等
+
+	TrustedProxies []string `toml:"trusted_proxies" json:"trustedProxies"` // 可信反向代理的 IP/网段，只有来自这些地址的请求才采用 X-Forwarded-For，默认不信任任何代理
 }
 
 type MonitorConfig struct {
@@ -28,27 +45,184 @@ type MonitorConfig struct {
 }
 
 type PgsqlConfig struct {
+	Host        string `toml:"host" json:"host"`
+	Port        string `toml:"port" json:"port"`
+	Username    string `toml:"username" json:"username"`
+	Password    string `toml:"password" json:"password"`
+	Database    string `toml:"database" json:"database"`
+	AutoMigrate bool   `toml:"auto_migrate" json:"autoMigrate"` // Deprecated: 使用 [datasource] auto_migrate
+
+	SslMode          string `toml:"sslmode" json:"sslmode"`                    // disable/require/verify-ca/verify-full，默认 disable
+	SslRootCert      string `toml:"sslrootcert" json:"sslrootcert"`            // CA证书路径，verify-ca/verify-full 时使用
+	ApplicationName  string `toml:"application_name" json:"applicationName"`   // 连接标识，默认使用应用名
+	SearchPath       string `toml:"search_path" json:"searchPath"`             // schema 搜索路径
+	ConnectTimeout   int    `toml:"connect_timeout" json:"connectTimeout"`     // 连接超时 单位：秒
+	StatementTimeout int    `toml:"statement_timeout" json:"statementTimeout"` // 语句超时 单位：毫秒
+	PoolConfig
+
+	Replicas                []string `toml:"replicas" json:"-"`                                         // 从库连接串，配置后读操作走从库
+	ReplicaMaxLag           int      `toml:"replica_max_lag" json:"replicaMaxLag"`                      // 从库最大允许延迟 单位：秒，超过则不再读该从库，0 表示不检查
+	ReplicaLagCheckInterval int      `toml:"replica_lag_check_interval" json:"replicaLagCheckInterval"` // 从库延迟检查间隔 单位：秒
+}
+
+// DataSourceConfig 数据源配置
+type DataSourceConfig struct {
+	Primary     string `toml:"primary" json:"primary"`          // 主数据源名称 pgsql/mysql/sqlite，默认 pgsql
+	AutoMigrate bool   `toml:"auto_migrate" json:"autoMigrate"` // 启动时自动执行数据库迁移，迁移脚本依赖 PostgreSQL，主数据源须为 pgsql
+}
+
+// PoolConfig 连接池及启动重试配置
+type PoolConfig struct {
+	MaxOpenConns    int `toml:"max_open_conns" json:"maxOpenConns"`        // 最大连接数 0 表示不限制
+	MaxIdleConns    int `toml:"max_idle_conns" json:"maxIdleConns"`        // 最大空闲连接数
+	ConnMaxLifetime int `toml:"conn_max_lifetime" json:"connMaxLifetime"`  // 连接最大存活时间 单位：秒
+	ConnMaxIdleTime int `toml:"conn_max_idle_time" json:"connMaxIdleTime"` // 连接最大空闲时间 单位：秒
+	RetryTimes      int `toml:"retry_times" json:"retryTimes"`             // 启动时连接失败重试次数
+	RetryInterval   int `toml:"retry_interval" json:"retryInterval"`       // 启动时首次重试间隔 单位：秒，之后指数退避
+}
+
+type MysqlConfig struct {
 	Host     string `toml:"host" json:"host"`
 	Port     string `toml:"port" json:"port"`
 	Username string `toml:"username" json:"username"`
 	Password string `toml:"password" json:"password"`
 	Database string `toml:"database" json:"database"`
+	Params   string `toml:"params" json:"params"` // 额外连接参数 如 charset=utf8mb4&parseTime=true&loc=Local
+	PoolConfig
+}
+
+type SqliteConfig struct {
+	Path string `toml:"path" json:"path"` // 数据库文件路径，":memory:" 为内存数据库（测试使用）
 }
 
 type RedisConfig struct {
-	Host        string `toml:"host" json:"host"`
-	Port        string `toml:"port" json:"port"`
-	Password    string `toml:"password" json:"password"`
-	Db          int    `toml:"db" json:"db"`
-	MaxIdle     int    `toml:"max_idle" json:"maxIdle"`
-	MaxActive   int    `toml:"max_active" json:"maxActive"`
-	IdleTimeout int    `toml:"idle_timeout" json:"idleTimeout"`
+	Mode        string   `toml:"mode" json:"mode"`                // 部署模式 standalone/sentinel/cluster，默认 standalone
+	Host        string   `toml:"host" json:"host"`                // standalone 模式地址
+	Port        string   `toml:"port" json:"port"`                // standalone 模式端口
+	Addrs       []string `toml:"addrs" json:"addrs"`              // sentinel 模式为哨兵地址，cluster 模式为种子节点地址 host:port
+	MasterName  string   `toml:"master_name" json:"masterName"`   // sentinel 模式主节点名称
+	Username    string   `toml:"username" json:"username"`        // ACL 用户名，为空时使用 default 用户
+	Password    string   `toml:"password" json:"password"`        // 密码
+	Db          int      `toml:"db" json:"db"`                    // cluster 模式仅支持 0
+	MaxIdle     int      `toml:"max_idle" json:"maxIdle"`         // 最大空闲连接数
+	MaxActive   int      `toml:"max_active" json:"maxActive"`     // 最大连接数 0 表示不限制
+	IdleTimeout int      `toml:"idle_timeout" json:"idleTimeout"` // 空闲连接超时 单位：秒
+
+	SentinelUsername string `toml:"sentinel_username" json:"sentinelUsername"` // 哨兵 ACL 用户名
+	SentinelPassword string `toml:"sentinel_password" json:"sentinelPassword"` // 哨兵密码
+
+	ConnectTimeout int `toml:"connect_timeout" json:"connectTimeout"` // 连接超时 单位：毫秒
+	ReadTimeout    int `toml:"read_timeout" json:"readTimeout"`       // 读超时 单位：毫秒
+	WriteTimeout   int `toml:"write_timeout" json:"writeTimeout"`     // 写超时 单位：毫秒
+	HealthCheck    int `toml:"health_check" json:"healthCheck"`       // 空闲超过该时间的连接取出时先检查可用性 单位：秒，0 表示每次检查
+
+	TLS           bool   `toml:"tls" json:"tls"`                       // 启用 TLS
+	TLSCAFile     string `toml:"tls_ca_file" json:"tlsCaFile"`         // CA 证书路径，为空使用系统证书
+	TLSServerName string `toml:"tls_server_name" json:"tlsServerName"` // 证书校验使用的服务名，为空时使用连接地址
+	TLSSkipVerify bool   `toml:"tls_skip_verify" json:"tlsSkipVerify"` // 跳过证书校验，仅用于测试
+}
+
+type CacheConfig struct {
+	Codec     string `toml:"codec" json:"codec"`          // 编解码 json/msgpack，默认 json
+	LocalSize int    `toml:"local_size" json:"localSize"` // 进程内一级缓存最大条数，0 表示不启用
+	LocalTTL  int    `toml:"local_ttl" json:"localTtl"`   // 进程内一级缓存过期时间 单位：秒
+
+	ChainFinalTTL   int `toml:"chain_final_ttl" json:"chainFinalTtl"`     // 已终局区块/交易/收据缓存时间 单位：秒，默认 24 小时
+	ChainUnfinalTTL int `toml:"chain_unfinal_ttl" json:"chainUnfinalTtl"` // 未终局区块/交易/收据缓存时间 单位：秒，默认 12 秒
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Enable bool                   `toml:"enable" json:"enable"`
+	Limit  int                    `toml:"limit" json:"limit"`   // 默认规则：period 内每个客户端最多请求次数
+	Period int                    `toml:"period" json:"period"` // 默认规则时间窗口 单位：秒
+	KeyBy  []string               `toml:"key_by" json:"keyBy"`  // 客户端标识 api_key/address/ip，按顺序取第一个存在的，默认 ["api_key", "address", "ip"]
+	Routes []RateLimitRouteConfig `toml:"routes" json:"routes"` // 按路由覆盖默认规则
+
+	AuthFailLimit  int `toml:"auth_fail_limit" json:"authFailLimit"`   // 每个IP auth_fail_period 内最多 API Key 校验失败次数，超过后不再校验直接拒绝，默认 10
+	AuthFailPeriod int `toml:"auth_fail_period" json:"authFailPeriod"` // 单位：秒，默认 60
+}
+
+// RateLimitRouteConfig 路由限流规则
+type RateLimitRouteConfig struct {
+	Method string `toml:"method" json:"method"` // 请求方法，为空匹配全部
+	Path   string `toml:"path" json:"path"`     // 路由路径，与注册的路由一致 如 /api/v1/demo/:id
+	Limit  int    `toml:"limit" json:"limit"`   // 0 表示该路由不限流
+	Period int    `toml:"period" json:"period"` // 单位：秒
+}
+
+// ReportConfig 错误上报配置，请求 panic 等错误事件上报到错误收集服务
+type ReportConfig struct {
+	Driver string `toml:"driver" json:"driver"` // file(本地文件，未接入 Sentry 时使用)/none，默认 file
+	Path   string `toml:"path" json:"path"`     // file 驱动的文件路径，默认 logs/{app}-errors.log
+}
+
+// AuditConfig 审计日志配置，记录已登记表的新增、更新、删除
+type AuditConfig struct {
+	Enable  bool `toml:"enable" json:"enable"`
+	MaxRows int  `toml:"max_rows" json:"maxRows"` // 单条更新/删除语句最多影响的行数，超过时语句失败，默认 100
+}
+
+// RetentionConfig 逻辑删除数据保留配置，定时永久删除删除时间超过保留期的记录
+type RetentionConfig struct {
+	Enable    bool `toml:"enable" json:"enable"`
+	Days      int  `toml:"days" json:"days"`            // 已删除记录保留天数，默认 30
+	Interval  int  `toml:"interval" json:"interval"`    // 清理间隔 单位：秒，默认 3600
+	BatchSize int  `toml:"batch_size" json:"batchSize"` // 每批删除的最大条数，默认 100
+}
+
+// CompressConfig 响应压缩配置，按请求头 Accept-Encoding 协商 br/gzip
+type CompressConfig struct {
+	Enable    bool     `toml:"enable" json:"enable"`
+	MinSize   int      `toml:"min_size" json:"minSize"`    // 响应体达到该大小才压缩 单位：字节，默认 1024
+	Encodings []string `toml:"encodings" json:"encodings"` // 支持的编码，按优先级排列，默认 ["br", "gzip"]
+	Level     int      `toml:"level" json:"level"`         // 压缩级别，0 使用各编码的默认级别
+}
+
+// IdempotencyConfig 幂等配置，携带 Idempotency-Key 请求头的 POST/PUT/PATCH/DELETE 请求只执行一次
+type IdempotencyConfig struct {
+	Enable  bool `toml:"enable" json:"enable"`
+	TTL     int  `toml:"ttl" json:"ttl"`          // 响应保存时间 单位：秒，默认 86400
+	LockTTL int  `toml:"lock_ttl" json:"lockTtl"` // 处理锁过期时间 单位：秒，处理期间自动续期，进程异常退出时最长 lock_ttl 后释放，默认 60
+}
+
+// TimeoutConfig 请求超时配置，超时后取消请求 context，数据库、Redis及链上RPC调用随之中止
+type TimeoutConfig struct {
+	Default int                  `toml:"default" json:"default"` // 默认请求超时 单位：毫秒，0 表示不限制
+	Routes  []TimeoutRouteConfig `toml:"routes" json:"routes"`   // 按路由覆盖默认超时
+}
+
+type TimeoutRouteConfig struct {
+	Method  string `toml:"method" json:"method"`   // 请求方法，为空匹配全部
+	Path    string `toml:"path" json:"path"`       // 路由路径，与注册的路由一致 如 /api/v1/demo/:id
+	Timeout int    `toml:"timeout" json:"timeout"` // 单位：毫秒，0 表示该路由不限制
+}
+
+// CorsConfig 跨域配置
+type CorsConfig struct {
+	AllowOrigins     []string `toml:"allow_origins" json:"allowOrigins"`         // 允许的来源，支持通配子域名 https://*.example.com，"*" 允许全部；非 dev 环境必须配置
+	AllowMethods     []string `toml:"allow_methods" json:"allowMethods"`         // 允许的请求方法，为空使用默认
+	AllowHeaders     []string `toml:"allow_headers" json:"allowHeaders"`         // 允许的请求头，为空使用默认
+	ExposeHeaders    []string `toml:"expose_headers" json:"exposeHeaders"`       // 暴露给前端的响应头，为空使用默认
+	AllowCredentials *bool    `toml:"allow_credentials" json:"allowCredentials"` // 是否允许携带凭证，默认 false，允许全部来源时强制 false
+	MaxAge           int      `toml:"max_age" json:"maxAge"`                     // 预检请求缓存时间 单位：秒，默认 3600
+}
+
+// MQConfig 消息队列配置
+type MQConfig struct {
+	Driver     string `toml:"driver" json:"driver"`          // redis/memory，默认 redis
+	MaxRetries int    `toml:"max_retries" json:"maxRetries"` // 处理失败最大重试次数，超过后进入死信队列
+	RetryDelay int    `toml:"retry_delay" json:"retryDelay"` // 处理失败后重新投递的等待时间 单位：秒
+	MaxLen     int64  `toml:"max_len" json:"maxLen"`         // 每个 topic 保留的最大消息数
+	Batch      int    `toml:"batch" json:"batch"`            // 每次拉取消息数
 }
 
 type ChainConfig struct {
 	Name     string `toml:"name" json:"name"`
 	ChainId  int    `toml:"chain_id" json:"chainId"`
 	Endpoint string `toml:"endpoint" json:"endpoint"`
+
+	Confirmations uint64 `toml:"confirmations" json:"confirmations"` // 节点不支持 finalized 标签时，距最新区块多少个确认视为终局，默认 64
 }
 
 // InitConfig 初始化配置
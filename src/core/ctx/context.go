@@ -1,11 +1,14 @@
 package ctx
 
 import (
+	"bossfi-backend/src/core/cache"
 	"bossfi-backend/src/core/chainclient"
 	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/lock"
+	"bossfi-backend/src/core/mq"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/gin-gonic/gin"
-	"github.com/gomodule/redigo/redis"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -15,7 +18,10 @@ var Ctx = Context{}
 type Context struct {
 	Config   *config.Config
 	DB       *gorm.DB
-	Redis    *redis.Pool
+	Redis    db.RedisClient
+	Cache    *cache.Cache
+	Lock     *lock.Locker
+	MQ       mq.Bus
 	Log      *zap.Logger
 	ChainMap map[int]*chainclient.ChainClient
 	Gin      *gin.Engine
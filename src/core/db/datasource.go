@@ -0,0 +1,152 @@
+package db
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/log"
+	"database/sql"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 数据源名称
+const (
+	SourcePgsql  = "pgsql"
+	SourceMysql  = "mysql"
+	SourceSqlite = "sqlite"
+)
+
+const (
+	// maxRetryInterval 连接重试最大间隔
+	maxRetryInterval = 30 * time.Second
+)
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = map[string]*gorm.DB{}
+)
+
+// InitDataSources 初始化已配置的数据源（主数据源及配置了连接信息的数据源），并设置主数据源 DB
+func InitDataSources() *gorm.DB {
+	conf := config.Conf
+	primary := PrimaryName()
+
+	if primary == SourcePgsql || conf.Pgsql.Host != "" {
+		InitPgsql()
+	}
+	if primary == SourceMysql || conf.Mysql.Host != "" {
+		InitMysql()
+	}
+	if primary == SourceSqlite || conf.Sqlite.Path != "" {
+		InitSqlite()
+	}
+
+	db, ok := Get(primary)
+	if !ok {
+		panic(fmt.Sprintf("unknown primary data source %q, available: %v", primary, Names()))
+	}
+	log.Logger.Info("primary data source", zap.String("name", primary))
+	DB = db
+	return db
+}
+
+// PrimaryName 主数据源名称，未配置时为 pgsql
+func PrimaryName() string {
+	if name := config.Conf.DataSource.Primary; name != "" {
+		return name
+	}
+	return SourcePgsql
+}
+
+// Register 注册数据源
+func Register(name string, db *gorm.DB) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	sources[name] = db
+}
+
+// Get 按名称获取数据源
+func Get(name string) (*gorm.DB, bool) {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	db, ok := sources[name]
+	return db, ok
+}
+
+// MustGet 按名称获取数据源，不存在时 panic
+func MustGet(name string) *gorm.DB {
+	db, ok := Get(name)
+	if !ok {
+		panic(fmt.Sprintf("data source %q not initialized", name))
+	}
+	return db
+}
+
+// Names 已注册的数据源名称
+func Names() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// open 打开数据源，启动时连接失败按退避间隔重试，成功后配置连接池
+func open(name string, dialector gorm.Dialector, pool config.PoolConfig) (*gorm.DB, error) {
+	gormConfig := &gorm.Config{}
+	if os.Getenv("GORM_DEBUG") == "true" {
+		gormConfig.Logger = logger.Default.LogMode(logger.Info)
+	}
+
+	// 启动时数据库短暂不可用则按退避间隔重试
+	interval := time.Duration(pool.RetryInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+	var db *gorm.DB
+	var err error
+	for attempt := 0; ; attempt++ {
+		db, err = gorm.Open(dialector, gormConfig)
+		if err == nil || attempt >= pool.RetryTimes {
+			break
+		}
+		log.Logger.Warn("connect data source failed, retrying", zap.String("name", name),
+			zap.Int("attempt", attempt+1), zap.Duration("interval", interval), zap.Error(err))
+		time.Sleep(interval)
+		interval = min(interval*2, maxRetryInterval)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	configurePool(sqlDB, pool)
+	return db, nil
+}
+
+// configurePool 配置连接池
+func configurePool(sqlDB *sql.DB, conf config.PoolConfig) {
+	if conf.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(conf.MaxOpenConns)
+	}
+	if conf.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(conf.MaxIdleConns)
+	}
+	if conf.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(conf.ConnMaxLifetime) * time.Second)
+	}
+	if conf.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(time.Duration(conf.ConnMaxIdleTime) * time.Second)
+	}
+}
@@ -1,14 +1,20 @@
 package db
 
 import (
-	"github.com/gomodule/redigo/redis"
 	"gorm.io/gorm"
 )
 
-// Mysql MySQL数据源备用
+// Mysql MySQL数据源
 var Mysql *gorm.DB
+
+// Pgsql PostgreSQL数据源
 var Pgsql *gorm.DB
-var Redis *redis.Pool
 
-// DB 主数据源使用pgsql
-var DB = Pgsql
+// Sqlite SQLite数据源，用于测试
+var Sqlite *gorm.DB
+
+// Redis Redis客户端，InitRedis 后可用
+var Redis RedisClient
+
+// DB 主数据源，由 [datasource] primary 指定，默认 pgsql，InitDataSources 后可用
+var DB *gorm.DB
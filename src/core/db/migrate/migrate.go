@@ -0,0 +1,355 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// lockKey 迁移使用的 PostgreSQL advisory lock key，保证多副本同时启动时只有一个实例执行迁移
+	lockKey int64 = 0x626f737366690001
+
+	migrationTable = "schema_migrations"
+	seedTable      = "schema_seeds"
+)
+
+// fileRegexp 迁移文件命名 {version}_{name}.up.sql / {version}_{name}.down.sql，种子文件 {version}_{name}.sql
+var fileRegexp = regexp.MustCompile(`^(\d+)_(\w+?)(\.up|\.down)?\.sql$`)
+
+// Migration 单个版本的迁移
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // up 脚本的 sha256
+}
+
+// Status 迁移状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Migrator 数据库迁移执行器
+type Migrator struct {
+	db         *sql.DB
+	migrations []*Migration
+	seeds      fs.FS
+}
+
+// New 创建迁移执行器 schema 为迁移脚本目录，seeds 为按环境划分的种子数据目录（可为 nil）
+func New(db *sql.DB, schema fs.FS, seeds fs.FS) (*Migrator, error) {
+	migrations, err := load(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, seeds: seeds}, nil
+}
+
+// load 读取并按版本排序迁移脚本
+func load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := fileRegexp.FindStringSubmatch(e.Name())
+		if m == nil || m[3] == "" {
+			return nil, fmt.Errorf("invalid migration file name %s", e.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == ".up" {
+			mig.Up = string(data)
+			mig.Checksum = checksum(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s missing up script", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up 执行全部未应用的迁移，执行前校验已应用迁移的 checksum
+func (m *Migrator) Up(ctx context.Context, log func(string)) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					"INSERT INTO "+migrationTable+" (version, name, checksum) VALUES ($1, $2, $3)",
+					mig.Version, mig.Name, mig.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migrate up %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			log(fmt.Sprintf("migrate up %d_%s", mig.Version, mig.Name))
+		}
+		return nil
+	})
+}
+
+// Down 回滚最近 steps 个已应用的迁移
+func (m *Migrator) Down(ctx context.Context, steps int, log func(string)) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down script", mig.Version, mig.Name)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, "DELETE FROM "+migrationTable+" WHERE version = $1", mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migrate down %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			log(fmt.Sprintf("migrate down %d_%s", mig.Version, mig.Name))
+			steps--
+		}
+		return nil
+	})
+}
+
+// Status 查询全部迁移的应用状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if a, ok := applied[mig.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = &a.appliedAt
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// Seed 按版本顺序执行指定环境未执行过的种子数据脚本 seeds/{env}/*.sql
+func (m *Migrator) Seed(ctx context.Context, env string, log func(string)) error {
+	if m.seeds == nil {
+		return nil
+	}
+	entries, err := fs.ReadDir(m.seeds, env)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			log("no seed data for env " + env)
+			return nil
+		}
+		return err
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+seedTable+` (
+			env        varchar      NOT NULL,
+			version    bigint       NOT NULL,
+			name       varchar      NOT NULL,
+			checksum   varchar(64)  NOT NULL,
+			applied_at timestamp(6) NOT NULL DEFAULT now(),
+			PRIMARY KEY (env, version)
+		)`); err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			match := fileRegexp.FindStringSubmatch(e.Name())
+			if e.IsDir() || match == nil || match[3] != "" {
+				continue
+			}
+			version, _ := strconv.ParseInt(match[1], 10, 64)
+			data, err := fs.ReadFile(m.seeds, path.Join(env, e.Name()))
+			if err != nil {
+				return err
+			}
+
+			var exists bool
+			err = conn.QueryRowContext(ctx,
+				"SELECT EXISTS (SELECT 1 FROM "+seedTable+" WHERE env = $1 AND version = $2)", env, version).Scan(&exists)
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+
+			err = inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, string(data)); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					"INSERT INTO "+seedTable+" (env, version, name, checksum) VALUES ($1, $2, $3, $4)",
+					env, version, match[2], checksum(data))
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("seed %s/%s: %w", env, e.Name(), err)
+			}
+			log(fmt.Sprintf("seed %s/%s", env, e.Name()))
+		}
+		return nil
+	})
+}
+
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// applied 查询已应用的迁移，迁移记录表不存在时自动创建
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+migrationTable+` (
+		version    bigint       PRIMARY KEY,
+		name       varchar      NOT NULL,
+		checksum   varchar(64)  NOT NULL,
+		applied_at timestamp(6) NOT NULL DEFAULT now()
+	)`); err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM "+migrationTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedMigration{}
+	for rows.Next() {
+		var version int64
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+// verify 校验已应用迁移脚本未被修改
+func (m *Migrator) verify(applied map[int64]appliedMigration) error {
+	known := make(map[int64]bool, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		if a, ok := applied[mig.Version]; ok && a.checksum != mig.Checksum {
+			return fmt.Errorf("checksum mismatch for applied migration %d_%s, migration files must not be modified once applied", mig.Version, mig.Name)
+		}
+	}
+	for version, a := range applied {
+		if !known[version] {
+			return fmt.Errorf("applied migration %d_%s not found in migration files", version, a.name)
+		}
+	}
+	return nil
+}
+
+// withLock 持有 advisory lock 执行 fn，锁与 fn 使用同一连接
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+	}()
+
+	return fn(conn)
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
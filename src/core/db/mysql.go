@@ -0,0 +1,39 @@
+package db
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/log"
+	"fmt"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// InitMysql 初始化MySQL
+func InitMysql() *gorm.DB {
+	log.Logger.Info("Init Mysql")
+	conf := config.Conf.Mysql
+	db, err := open(SourceMysql, mysql.Open(MysqlDSN(conf)), conf.PoolConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	Mysql = db
+	Register(SourceMysql, db)
+	return db
+}
+
+// MysqlDSN 根据配置生成连接串
+func MysqlDSN(conf config.MysqlConfig) string {
+	params := conf.Params
+	if params == "" {
+		params = "charset=utf8mb4&parseTime=true&loc=Local"
+	}
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s",
+		conf.Username,
+		conf.Password,
+		conf.Host,
+		conf.Port,
+		conf.Database,
+		params,
+	)
+}
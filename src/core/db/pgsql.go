@@ -6,28 +6,70 @@ import (
 	"fmt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
-	"os"
+	"strings"
 )
 
 func InitPgsql() *gorm.DB {
 	log.Logger.Info("Init Pgsql")
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		config.Conf.Pgsql.Host,
-		config.Conf.Pgsql.Username,
-		config.Conf.Pgsql.Password,
-		config.Conf.Pgsql.Database,
-		config.Conf.Pgsql.Port,
-	)
-
-	gormConfig := &gorm.Config{}
-	if os.Getenv("GORM_DEBUG") == "true" {
-		gormConfig.Logger = logger.Default.LogMode(logger.Info)
-	}
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	conf := config.Conf.Pgsql
+	db, err := open(SourcePgsql, postgres.Open(PgsqlDSN(conf)), conf.PoolConfig)
 	if err != nil {
 		panic(err)
 	}
-	DB = db
+
+	// 读写分离
+	if err := useReplicas(db, conf); err != nil {
+		panic(err)
+	}
+
+	Pgsql = db
+	Register(SourcePgsql, db)
 	return db
 }
+
+// PgsqlDSN 根据配置生成连接串
+func PgsqlDSN(conf config.PgsqlConfig) string {
+	sslMode := conf.SslMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	applicationName := conf.ApplicationName
+	if applicationName == "" {
+		applicationName = config.Conf.App.Name
+	}
+
+	params := [][2]string{
+		{"host", conf.Host},
+		{"port", conf.Port},
+		{"user", conf.Username},
+		{"password", conf.Password},
+		{"dbname", conf.Database},
+		{"sslmode", sslMode},
+		{"sslrootcert", conf.SslRootCert},
+		{"application_name", applicationName},
+		{"search_path", conf.SearchPath},
+	}
+	if conf.ConnectTimeout > 0 {
+		params = append(params, [2]string{"connect_timeout", fmt.Sprint(conf.ConnectTimeout)})
+	}
+	if conf.StatementTimeout > 0 {
+		params = append(params, [2]string{"statement_timeout", fmt.Sprint(conf.StatementTimeout)})
+	}
+
+	var parts []string
+	for _, p := range params {
+		if p[1] == "" {
+			continue
+		}
+		parts = append(parts, p[0]+"="+quoteDSNValue(p[1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue 转义连接串参数值，包含空格、引号等字符时使用单引号包裹
+func quoteDSNValue(v string) string {
+	if !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
@@ -3,47 +3,257 @@ package db
 import (
 	"bossfi-backend/src/core/config"
 	"bossfi-backend/src/core/log"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"github.com/gomodule/redigo/redis"
-	"strings"
+	"go.uber.org/zap"
+	"net"
+	"os"
+	"sync"
 	"time"
 )
 
-var RedisConn *redis.Pool
+// Redis 部署模式
+const (
+	RedisStandalone = "standalone"
+	RedisSentinel   = "sentinel"
+	RedisCluster    = "cluster"
+)
+
+// RedisClient Redis客户端，standalone/sentinel 模式为 *redis.Pool，cluster 模式为 *RedisClusterClient
+type RedisClient interface {
+	Get() redis.Conn
+	GetContext(ctx context.Context) (redis.Conn, error)
+	Close() error
+}
+
+var RedisConn RedisClient
 
-// InitRedis 初始化Redis
-func InitRedis() *redis.Pool {
-	log.Logger.Info("Init Redis")
+// InitRedis 初始化Redis，启动时连接失败直接退出
+func InitRedis() RedisClient {
 	redisConf := config.Conf.Redis
-	// 建立连接池
-	RedisConn = &redis.Pool{
-		MaxIdle:     redisConf.MaxIdle,   // 最大的空闲连接数，表示即使没有redis连接时依然可以保持N个空闲的连接，而不被清除，随时处于待命状态。
-		MaxActive:   redisConf.MaxActive, // 最大的激活连接数，表示同时最多有N个连接   0 表示无穷大
-		Wait:        true,                // 如果连接数不足则阻塞等待
-		IdleTimeout: time.Duration(redisConf.IdleTimeout) * time.Second,
-		Dial: func() (redis.Conn, error) {
-			c, err := redis.Dial("tcp", fmt.Sprintf("%s:%s", redisConf.Host, redisConf.Port))
-			if err != nil {
-				return nil, err
-			}
-			// 如指定密码，则验证密码
-			if strings.TrimSpace(redisConf.Password) != "" {
-				_, err = c.Do("auth", redisConf.Password)
-				if err != nil {
-					panic("redis auth err " + err.Error())
-				}
-			}
-			// 选择db
-			_, err = c.Do("select", redisConf.Db)
-			if err != nil {
-				panic("redis select db err " + err.Error())
+	log.Logger.Info("Init Redis", zap.String("mode", redisMode(redisConf)))
+
+	client, err := NewRedis(redisConf)
+	if err != nil {
+		panic("redis init err " + err.Error())
+	}
+	if err := pingRedis(client); err != nil {
+		panic("redis init err " + err.Error())
+	}
+	RedisConn = client
+	Redis = client
+	return client
+}
+
+// NewRedis 按配置的部署模式创建Redis客户端，连接错误由 Dial 返回，不会在连接池内 panic
+func NewRedis(conf config.RedisConfig) (RedisClient, error) {
+	opts, err := redisDialOptions(conf)
+	if err != nil {
+		return nil, err
+	}
+	nodeOpts := append(redisAuthOptions(conf.Username, conf.Password), opts...)
+
+	switch redisMode(conf) {
+	case RedisStandalone:
+		addr := net.JoinHostPort(conf.Host, conf.Port)
+		nodeOpts = append(nodeOpts, redis.DialDatabase(conf.Db))
+		return newRedisPool(conf, func(ctx context.Context) (redis.Conn, error) {
+			return redis.DialContext(ctx, "tcp", addr, nodeOpts...)
+		}, pingCheck), nil
+	case RedisSentinel:
+		if conf.MasterName == "" || len(conf.Addrs) == 0 {
+			return nil, errors.New("redis sentinel mode requires master_name and addrs")
+		}
+		s := &redisSentinel{
+			addrs:      append([]string(nil), conf.Addrs...),
+			masterName: conf.MasterName,
+			opts:       append(redisAuthOptions(conf.SentinelUsername, conf.SentinelPassword), opts...),
+			nodeOpts:   append(nodeOpts, redis.DialDatabase(conf.Db)),
+		}
+		return newRedisPool(conf, s.dial, masterCheck), nil
+	case RedisCluster:
+		if len(conf.Addrs) == 0 {
+			return nil, errors.New("redis cluster mode requires addrs")
+		}
+		if conf.Db != 0 {
+			return nil, errors.New("redis cluster mode only supports db 0")
+		}
+		return newRedisCluster(conf, nodeOpts)
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", conf.Mode)
+	}
+}
+
+func redisMode(conf config.RedisConfig) string {
+	if conf.Mode == "" {
+		return RedisStandalone
+	}
+	return conf.Mode
+}
+
+// newRedisPool 创建连接池，取出空闲超过 health_check 的连接时先执行 check
+func newRedisPool(conf config.RedisConfig, dial func(ctx context.Context) (redis.Conn, error), check func(c redis.Conn) error) *redis.Pool {
+	healthCheck := time.Duration(conf.HealthCheck) * time.Second
+	return &redis.Pool{
+		MaxIdle:     conf.MaxIdle,   // 最大的空闲连接数，表示即使没有redis连接时依然可以保持N个空闲的连接，而不被清除，随时处于待命状态。
+		MaxActive:   conf.MaxActive, // 最大的激活连接数，表示同时最多有N个连接   0 表示无穷大
+		Wait:        true,           // 如果连接数不足则阻塞等待
+		IdleTimeout: time.Duration(conf.IdleTimeout) * time.Second,
+		DialContext: dial,
+		TestOnBorrow: func(c redis.Conn, lastUsed time.Time) error {
+			if time.Since(lastUsed) < healthCheck {
+				return nil
 			}
-			return c, nil
+			return check(c)
 		},
 	}
-	err := RedisConn.Get().Err()
+}
+
+// redisDialOptions 超时及TLS连接选项
+func redisDialOptions(conf config.RedisConfig) ([]redis.DialOption, error) {
+	var opts []redis.DialOption
+	if conf.ConnectTimeout > 0 {
+		opts = append(opts, redis.DialConnectTimeout(time.Duration(conf.ConnectTimeout)*time.Millisecond))
+	}
+	if conf.ReadTimeout > 0 {
+		opts = append(opts, redis.DialReadTimeout(time.Duration(conf.ReadTimeout)*time.Millisecond))
+	}
+	if conf.WriteTimeout > 0 {
+		opts = append(opts, redis.DialWriteTimeout(time.Duration(conf.WriteTimeout)*time.Millisecond))
+	}
+	if !conf.TLS {
+		return opts, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         conf.TLSServerName,
+		InsecureSkipVerify: conf.TLSSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if conf.TLSCAFile != "" {
+		pem, err := os.ReadFile(conf.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read redis tls ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in redis tls ca file %s", conf.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return append(opts,
+		redis.DialUseTLS(true),
+		redis.DialTLSConfig(tlsConfig),
+		redis.DialTLSSkipVerify(conf.TLSSkipVerify),
+	), nil
+}
+
+// redisAuthOptions 认证选项，配置用户名时使用 ACL 认证 AUTH username password
+func redisAuthOptions(username, password string) []redis.DialOption {
+	var opts []redis.DialOption
+	if username != "" {
+		opts = append(opts, redis.DialUsername(username))
+	}
+	if password != "" {
+		opts = append(opts, redis.DialPassword(password))
+	}
+	return opts
+}
+
+func pingCheck(c redis.Conn) error {
+	_, err := c.Do("PING")
+	return err
+}
+
+// masterCheck 检查连接的节点仍为主节点，主从切换后旧主节点的连接将被丢弃
+func masterCheck(c redis.Conn) error {
+	role, err := redis.Values(c.Do("ROLE"))
 	if err != nil {
-		panic("redis init err " + err.Error())
+		return err
+	}
+	if len(role) == 0 {
+		return errors.New("redis: empty ROLE reply")
+	}
+	if r, _ := redis.String(role[0], nil); r != "master" {
+		return fmt.Errorf("redis: node role is %s, not master", r)
+	}
+	return nil
+}
+
+// pingRedis 启动时检查连接可用
+func pingRedis(client RedisClient) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := client.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = redis.DoContext(conn, ctx, "PING")
+	return err
+}
+
+// redisSentinel 通过哨兵发现主节点
+type redisSentinel struct {
+	mu         sync.Mutex
+	addrs      []string
+	masterName string
+	opts       []redis.DialOption // 连接哨兵的选项
+	nodeOpts   []redis.DialOption // 连接主节点的选项
+}
+
+// dial 连接当前主节点
+func (s *redisSentinel) dial(ctx context.Context) (redis.Conn, error) {
+	addr, err := s.masterAddr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, err := redis.DialContext(ctx, "tcp", addr, s.nodeOpts...)
+	if err != nil {
+		return nil, err
+	}
+	// 哨兵信息可能滞后于主从切换，确认连接的是主节点
+	if err := masterCheck(c); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// masterAddr 依次询问哨兵获取主节点地址，成功的哨兵移到首位
+func (s *redisSentinel) masterAddr(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	addrs := append([]string(nil), s.addrs...)
+	s.mu.Unlock()
+
+	var lastErr error
+	for i, addr := range addrs {
+		c, err := redis.DialContext(ctx, "tcp", addr, s.opts...)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		res, err := redis.Strings(redis.DoContext(c, ctx, "SENTINEL", "get-master-addr-by-name", s.masterName))
+		c.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(res) != 2 {
+			lastErr = fmt.Errorf("invalid sentinel reply %v", res)
+			continue
+		}
+
+		if i > 0 {
+			s.mu.Lock()
+			s.addrs[0], s.addrs[i] = s.addrs[i], s.addrs[0]
+			s.mu.Unlock()
+		}
+		return net.JoinHostPort(res[0], res[1]), nil
 	}
-	return RedisConn
+	return "", fmt.Errorf("get redis master %s from sentinels: %w", s.masterName, lastErr)
 }
@@ -0,0 +1,447 @@
+package db
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/log"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"go.uber.org/zap"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// clusterSlots 集群哈希槽数量
+	clusterSlots = 16384
+	// clusterMaxRedirects 单条命令最大重定向次数
+	clusterMaxRedirects = 5
+)
+
+var errClusterConnClosed = errors.New("redis: cluster connection closed")
+
+// RedisClusterClient Redis集群客户端，按key所在哈希槽将命令路由到对应节点，自动处理 MOVED/ASK 重定向
+//
+// 每条命令独立路由，因此不支持跨槽的多key命令和 MULTI/EXEC 事务，需要原子性时使用单key或 {hash tag} 的 Lua 脚本
+type RedisClusterClient struct {
+	conf     config.RedisConfig
+	seeds    []string
+	nodeOpts []redis.DialOption
+
+	mu         sync.RWMutex
+	slots      [clusterSlots]string
+	pools      map[string]*redis.Pool
+	refreshing atomic.Bool
+}
+
+// newRedisCluster 创建集群客户端并加载槽位分布
+func newRedisCluster(conf config.RedisConfig, nodeOpts []redis.DialOption) (*RedisClusterClient, error) {
+	c := &RedisClusterClient{
+		conf:     conf,
+		seeds:    append([]string(nil), conf.Addrs...),
+		nodeOpts: nodeOpts,
+		pools:    map[string]*redis.Pool{},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Get 获取集群连接
+func (c *RedisClusterClient) Get() redis.Conn {
+	return &clusterConn{cluster: c, ctx: context.Background()}
+}
+
+// GetContext 获取绑定 context 的集群连接
+func (c *RedisClusterClient) GetContext(ctx context.Context) (redis.Conn, error) {
+	return &clusterConn{cluster: c, ctx: ctx}, nil
+}
+
+// Close 关闭全部节点连接池
+func (c *RedisClusterClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var err error
+	for _, pool := range c.pools {
+		if e := pool.Close(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// pool 获取节点连接池，不存在时创建
+func (c *RedisClusterClient) pool(addr string) *redis.Pool {
+	c.mu.RLock()
+	pool, ok := c.pools[addr]
+	c.mu.RUnlock()
+	if ok {
+		return pool
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pool, ok := c.pools[addr]; ok {
+		return pool
+	}
+	pool = newRedisPool(c.conf, func(ctx context.Context) (redis.Conn, error) {
+		return redis.DialContext(ctx, "tcp", addr, c.nodeOpts...)
+	}, pingCheck)
+	c.pools[addr] = pool
+	return pool
+}
+
+// refresh 通过 CLUSTER SLOTS 重新加载槽位分布，优先询问已知节点
+func (c *RedisClusterClient) refresh(ctx context.Context) error {
+	c.mu.RLock()
+	addrs := make([]string, 0, len(c.pools)+len(c.seeds))
+	for addr := range c.pools {
+		addrs = append(addrs, addr)
+	}
+	c.mu.RUnlock()
+	addrs = append(addrs, c.seeds...)
+
+	var lastErr error
+	for _, addr := range addrs {
+		slots, err := c.loadSlots(ctx, addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		c.mu.Lock()
+		c.slots = slots
+		c.mu.Unlock()
+		return nil
+	}
+	return fmt.Errorf("load redis cluster slots: %w", lastErr)
+}
+
+// refreshAsync 后台刷新槽位分布，同一时间只执行一次
+func (c *RedisClusterClient) refreshAsync() {
+	if !c.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer c.refreshing.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := c.refresh(ctx); err != nil {
+			log.Logger.Warn("refresh redis cluster slots error", zap.Error(err))
+		}
+	}()
+}
+
+func (c *RedisClusterClient) loadSlots(ctx context.Context, addr string) ([clusterSlots]string, error) {
+	var slots [clusterSlots]string
+	conn, err := c.pool(addr).GetContext(ctx)
+	if err != nil {
+		return slots, err
+	}
+	defer conn.Close()
+
+	ranges, err := redis.Values(redis.DoContext(conn, ctx, "CLUSTER", "SLOTS"))
+	if err != nil {
+		return slots, err
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	for _, r := range ranges {
+		// [start, end, [host, port, id], replicas...]
+		fields, err := redis.Values(r, nil)
+		if err != nil || len(fields) < 3 {
+			return slots, fmt.Errorf("invalid CLUSTER SLOTS reply from %s", addr)
+		}
+		start, _ := redis.Int(fields[0], nil)
+		end, _ := redis.Int(fields[1], nil)
+		master, err := redis.Values(fields[2], nil)
+		if err != nil || len(master) < 2 {
+			return slots, fmt.Errorf("invalid CLUSTER SLOTS reply from %s", addr)
+		}
+		nodeHost, _ := redis.String(master[0], nil)
+		nodePort, _ := redis.Int(master[1], nil)
+		// 节点未公布主机名时使用当前连接的主机
+		if nodeHost == "" || nodeHost == "?" {
+			nodeHost = host
+		}
+		nodeAddr := net.JoinHostPort(nodeHost, strconv.Itoa(nodePort))
+		for slot := start; slot <= end && slot < clusterSlots; slot++ {
+			slots[slot] = nodeAddr
+		}
+	}
+	return slots, nil
+}
+
+// nodeFor 命令路由的节点地址，无key的命令路由到任意节点
+func (c *RedisClusterClient) nodeFor(cmd string, args []interface{}) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if key, ok := commandKey(cmd, args); ok {
+		if addr := c.slots[keySlot(key)]; addr != "" {
+			return addr
+		}
+	}
+	for addr := range c.pools {
+		return addr
+	}
+	return c.seeds[rand.Intn(len(c.seeds))]
+}
+
+func (c *RedisClusterClient) setSlot(slot int, addr string) {
+	if slot < 0 || slot >= clusterSlots {
+		return
+	}
+	c.mu.Lock()
+	c.slots[slot] = addr
+	c.mu.Unlock()
+}
+
+// do 执行单条命令并处理重定向，timeout > 0 时覆盖读超时（用于阻塞命令）
+func (c *RedisClusterClient) do(ctx context.Context, timeout time.Duration, cmd string, args []interface{}) (interface{}, error) {
+	addr := c.nodeFor(cmd, args)
+	asking := false
+
+	var err error
+	for attempt := 0; attempt < clusterMaxRedirects; attempt++ {
+		var reply interface{}
+		reply, err = c.doOn(ctx, timeout, addr, asking, cmd, args)
+
+		var redisErr redis.Error
+		if !errors.As(err, &redisErr) {
+			// 节点不可用时槽位可能已迁移
+			if err != nil {
+				c.refreshAsync()
+			}
+			return reply, err
+		}
+
+		msg := string(redisErr)
+		switch {
+		case strings.HasPrefix(msg, "MOVED "):
+			slot, target := parseRedirect(msg)
+			c.setSlot(slot, target)
+			c.refreshAsync()
+			addr, asking = target, false
+		case strings.HasPrefix(msg, "ASK "):
+			_, target := parseRedirect(msg)
+			addr, asking = target, true
+		case strings.HasPrefix(msg, "TRYAGAIN"), strings.HasPrefix(msg, "CLUSTERDOWN"):
+			select {
+			case <-ctx.Done():
+				return nil, err
+			case <-time.After(10 * time.Millisecond << attempt):
+			}
+		default:
+			return reply, err
+		}
+	}
+	return nil, err
+}
+
+func (c *RedisClusterClient) doOn(ctx context.Context, timeout time.Duration, addr string, asking bool, cmd string, args []interface{}) (interface{}, error) {
+	conn, err := c.pool(addr).GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if asking {
+		if _, err := redis.DoContext(conn, ctx, "ASKING"); err != nil {
+			return nil, err
+		}
+	}
+	if timeout > 0 {
+		return redis.DoWithTimeout(conn, timeout, cmd, args...)
+	}
+	return redis.DoContext(conn, ctx, cmd, args...)
+}
+
+// parseRedirect 解析 MOVED/ASK 错误 "MOVED 3999 127.0.0.1:6381"
+func parseRedirect(msg string) (int, string) {
+	parts := strings.Fields(msg)
+	if len(parts) != 3 {
+		return -1, ""
+	}
+	slot, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return -1, parts[2]
+	}
+	return slot, parts[2]
+}
+
+// commandKey 命令的第一个key，用于计算路由槽位
+func commandKey(cmd string, args []interface{}) (string, bool) {
+	switch strings.ToUpper(cmd) {
+	case "EVAL", "EVALSHA", "EVAL_RO", "EVALSHA_RO", "FCALL", "FCALL_RO":
+		if len(args) > 2 {
+			if n, _ := strconv.Atoi(argString(args[1])); n > 0 {
+				return argString(args[2]), true
+			}
+		}
+		return "", false
+	case "XREAD", "XREADGROUP":
+		for i, arg := range args {
+			if strings.EqualFold(argString(arg), "STREAMS") && i+1 < len(args) {
+				return argString(args[i+1]), true
+			}
+		}
+		return "", false
+	case "PING", "ECHO", "INFO", "TIME", "ROLE", "SCRIPT", "CLUSTER", "CLIENT", "CONFIG", "COMMAND",
+		"DBSIZE", "PUBLISH", "WAIT", "MULTI", "EXEC", "DISCARD", "ASKING":
+		return "", false
+	}
+	if len(args) == 0 {
+		return "", false
+	}
+	return argString(args[0]), true
+}
+
+func argString(arg interface{}) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// keySlot key所在哈希槽，key中包含 {hash tag} 时仅计算 tag 部分
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) % clusterSlots
+}
+
+// crc16 CRC16-CCITT(XMODEM)，Redis集群槽位算法
+func crc16(data string) uint16 {
+	var crc uint16
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+type clusterReply struct {
+	reply interface{}
+	err   error
+}
+
+// clusterConn 集群连接，实现 redis.Conn 及 redis.ConnWithTimeout；Send 的命令在 Flush 时逐条路由执行，结果由 Receive 依次返回
+type clusterConn struct {
+	cluster *RedisClusterClient
+	ctx     context.Context
+	pending [][]interface{}
+	replies []clusterReply
+	err     error
+}
+
+func (c *clusterConn) Close() error {
+	c.err = errClusterConnClosed
+	c.pending, c.replies = nil, nil
+	return nil
+}
+
+func (c *clusterConn) Err() error {
+	return c.err
+}
+
+func (c *clusterConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	return c.DoWithTimeout(0, cmd, args...)
+}
+
+// DoWithTimeout 执行命令并覆盖读超时，用于 XREADGROUP BLOCK 等阻塞命令
+func (c *clusterConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if err := c.Flush(); err != nil {
+		return nil, err
+	}
+
+	// 与 redigo 一致：cmd 为空时返回全部未读取的结果
+	if cmd == "" {
+		replies := make([]interface{}, 0, len(c.replies))
+		for len(c.replies) > 0 {
+			reply, err := c.Receive()
+			if err != nil {
+				return nil, err
+			}
+			replies = append(replies, reply)
+		}
+		return replies, nil
+	}
+
+	// 丢弃未读取的结果，返回其中的第一个错误
+	var pendingErr error
+	for _, r := range c.replies {
+		if r.err != nil && pendingErr == nil {
+			pendingErr = r.err
+		}
+	}
+	c.replies = nil
+
+	reply, err := c.cluster.do(c.ctx, timeout, cmd, args)
+	if err == nil {
+		err = pendingErr
+	}
+	return reply, err
+}
+
+func (c *clusterConn) Send(cmd string, args ...interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.pending = append(c.pending, append([]interface{}{cmd}, args...))
+	return nil
+}
+
+func (c *clusterConn) Flush() error {
+	if c.err != nil {
+		return c.err
+	}
+	for _, p := range c.pending {
+		reply, err := c.cluster.do(c.ctx, 0, p[0].(string), p[1:])
+		c.replies = append(c.replies, clusterReply{reply: reply, err: err})
+	}
+	c.pending = nil
+	return nil
+}
+
+func (c *clusterConn) ReceiveWithTimeout(_ time.Duration) (interface{}, error) {
+	return c.Receive()
+}
+
+func (c *clusterConn) Receive() (interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if len(c.replies) == 0 {
+		if err := c.Flush(); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.replies) == 0 {
+		return nil, errors.New("redis: no pending replies")
+	}
+	r := c.replies[0]
+	c.replies = c.replies[1:]
+	return r.reply, r.err
+}
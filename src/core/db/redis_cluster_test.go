@@ -0,0 +1,347 @@
+package db
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/log"
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"go.uber.org/zap"
+)
+
+// fakeCluster 内存中的Redis集群，节点按槽位归属处理 GET/SET，不属于本节点的槽位返回 MOVED，
+// 迁移中的槽位返回 ASK，目标节点只在 ASKING 后接受迁移中槽位的命令
+type fakeCluster struct {
+	mu        sync.Mutex
+	nodes     []*fakeNode
+	owner     [clusterSlots]int // 槽位 -> 节点下标
+	migrating map[int]int       // 迁移中的槽位 -> 目标节点下标
+	tryAgain  int               // 剩余返回 TRYAGAIN 的次数
+	data      map[string]string
+}
+
+type fakeNode struct {
+	cluster *fakeCluster
+	index   int
+	addr    string
+	calls   atomic.Int64 // GET/SET 调用次数
+}
+
+func newFakeCluster(t *testing.T, n int) *fakeCluster {
+	t.Helper()
+	fc := &fakeCluster{migrating: map[int]int{}, data: map[string]string{}}
+	for i := 0; i < n; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = l.Close() })
+		node := &fakeNode{cluster: fc, index: i, addr: l.Addr().String()}
+		fc.nodes = append(fc.nodes, node)
+		go node.serve(l)
+	}
+	return fc
+}
+
+// assign 将全部槽位分配给节点
+func (fc *fakeCluster) assign(node int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	for i := range fc.owner {
+		fc.owner[i] = node
+	}
+}
+
+func (n *fakeNode) serve(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go n.handle(conn)
+	}
+}
+
+func (n *fakeNode) handle(conn net.Conn) {
+	defer conn.Close()
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	asking := false
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		reply := n.exec(args, asking)
+		asking = strings.EqualFold(args[0], "ASKING")
+		_, _ = w.WriteString(reply)
+		if w.Flush() != nil {
+			return
+		}
+	}
+}
+
+func (n *fakeNode) exec(args []string, asking bool) string {
+	fc := n.cluster
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "ASKING":
+		return "+OK\r\n"
+	case "CLUSTER":
+		return fc.slotsReply()
+	case "GET", "SET":
+	default:
+		return "-ERR unknown command\r\n"
+	}
+
+	n.calls.Add(1)
+	key := args[1]
+	slot := keySlot(key)
+	if fc.tryAgain > 0 {
+		fc.tryAgain--
+		return "-TRYAGAIN Multiple keys request during rehashing of slot\r\n"
+	}
+	if target, ok := fc.migrating[slot]; ok {
+		switch {
+		case n.index == fc.owner[slot]:
+			return fmt.Sprintf("-ASK %d %s\r\n", slot, fc.nodes[target].addr)
+		case n.index == target && !asking:
+			return fmt.Sprintf("-MOVED %d %s\r\n", slot, fc.nodes[fc.owner[slot]].addr)
+		}
+	} else if n.index != fc.owner[slot] {
+		return fmt.Sprintf("-MOVED %d %s\r\n", slot, fc.nodes[fc.owner[slot]].addr)
+	}
+
+	if strings.EqualFold(args[0], "SET") {
+		fc.data[key] = args[2]
+		return "+OK\r\n"
+	}
+	v, ok := fc.data[key]
+	if !ok {
+		return "$-1\r\n"
+	}
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+}
+
+// slotsReply CLUSTER SLOTS 回复，连续相同归属的槽位合并为一个区间
+func (fc *fakeCluster) slotsReply() string {
+	var ranges []string
+	start := 0
+	for slot := 1; slot <= clusterSlots; slot++ {
+		if slot < clusterSlots && fc.owner[slot] == fc.owner[start] {
+			continue
+		}
+		host, port, _ := net.SplitHostPort(fc.nodes[fc.owner[start]].addr)
+		ranges = append(ranges, fmt.Sprintf("*3\r\n:%d\r\n:%d\r\n*2\r\n$%d\r\n%s\r\n:%s\r\n",
+			start, slot-1, len(host), host, port))
+		start = slot
+	}
+	return fmt.Sprintf("*%d\r\n%s", len(ranges), strings.Join(ranges, ""))
+}
+
+// readCommand 读取 RESP 数组格式的命令
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected %q", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func newTestClusterClient(t *testing.T, fc *fakeCluster) *RedisClusterClient {
+	t.Helper()
+	log.Logger = zap.NewNop()
+	c, err := newRedisCluster(config.RedisConfig{Addrs: []string{fc.nodes[0].addr}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestKeySlot(t *testing.T) {
+	// Redis 集群规范中的校验值
+	if got := crc16("123456789"); got != 0x31C3 {
+		t.Fatalf("crc16 = %#x, want 0x31c3", got)
+	}
+	if keySlot("{user1000}.following") != keySlot("{user1000}.followers") {
+		t.Error("keys with the same hash tag map to different slots")
+	}
+	if keySlot("foo{}") != int(crc16("foo{}"))%clusterSlots {
+		t.Error("empty hash tag should hash the whole key")
+	}
+}
+
+func TestCommandKey(t *testing.T) {
+	for _, c := range []struct {
+		cmd  string
+		args []interface{}
+		key  string
+		ok   bool
+	}{
+		{"GET", []interface{}{"k"}, "k", true},
+		{"EVALSHA", []interface{}{"sha", 1, "k", "v"}, "k", true},
+		{"EVAL", []interface{}{"script", 0}, "", false},
+		{"XREADGROUP", []interface{}{"GROUP", "g", "c", "COUNT", 1, "STREAMS", "s", ">"}, "s", true},
+		{"PING", nil, "", false},
+	} {
+		key, ok := commandKey(c.cmd, c.args)
+		if key != c.key || ok != c.ok {
+			t.Errorf("commandKey(%s %v) = %q, %v, want %q, %v", c.cmd, c.args, key, ok, c.key, c.ok)
+		}
+	}
+}
+
+func TestClusterMoved(t *testing.T) {
+	fc := newFakeCluster(t, 2)
+	fc.assign(0)
+	c := newTestClusterClient(t, fc)
+
+	// 槽位迁移到节点1后，节点0返回 MOVED
+	fc.assign(1)
+	conn := c.Get()
+	defer conn.Close()
+	if _, err := conn.Do("SET", "k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if fc.nodes[0].calls.Load() != 1 || fc.nodes[1].calls.Load() != 1 {
+		t.Fatalf("calls = %d/%d, want 1/1", fc.nodes[0].calls.Load(), fc.nodes[1].calls.Load())
+	}
+
+	// MOVED 更新槽位，后续命令直接发往新节点
+	v, err := redis.String(conn.Do("GET", "k"))
+	if err != nil || v != "v" {
+		t.Fatalf("GET = %q, %v", v, err)
+	}
+	if fc.nodes[0].calls.Load() != 1 {
+		t.Errorf("node0 calls = %d, want 1", fc.nodes[0].calls.Load())
+	}
+}
+
+func TestClusterAsk(t *testing.T) {
+	fc := newFakeCluster(t, 2)
+	fc.assign(0)
+	c := newTestClusterClient(t, fc)
+
+	slot := keySlot("k")
+	fc.mu.Lock()
+	fc.migrating[slot] = 1
+	fc.mu.Unlock()
+
+	conn := c.Get()
+	defer conn.Close()
+	if _, err := conn.Do("SET", "k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	// ASK 只对本次命令生效，不更新槽位
+	c.mu.RLock()
+	owner := c.slots[slot]
+	c.mu.RUnlock()
+	if owner != fc.nodes[0].addr {
+		t.Errorf("slot owner = %s, want %s", owner, fc.nodes[0].addr)
+	}
+	v, err := redis.String(conn.Do("GET", "k"))
+	if err != nil || v != "v" {
+		t.Fatalf("GET = %q, %v", v, err)
+	}
+	if got := fc.nodes[0].calls.Load(); got != 2 {
+		t.Errorf("node0 calls = %d, want 2", got)
+	}
+}
+
+func TestClusterTryAgain(t *testing.T) {
+	fc := newFakeCluster(t, 1)
+	fc.assign(0)
+	c := newTestClusterClient(t, fc)
+
+	fc.mu.Lock()
+	fc.tryAgain = 2
+	fc.mu.Unlock()
+	conn := c.Get()
+	defer conn.Close()
+	if _, err := conn.Do("SET", "k", "v"); err != nil {
+		t.Fatal(err)
+	}
+
+	fc.mu.Lock()
+	fc.tryAgain = clusterMaxRedirects
+	fc.mu.Unlock()
+	if _, err := conn.Do("SET", "k", "v"); err == nil || !strings.HasPrefix(err.Error(), "TRYAGAIN") {
+		t.Errorf("SET after max retries = %v, want TRYAGAIN", err)
+	}
+}
+
+func TestClusterPipeline(t *testing.T) {
+	fc := newFakeCluster(t, 2)
+	fc.assign(0)
+	// 奇数槽位属于节点1，流水线中的命令分别路由到不同节点
+	fc.mu.Lock()
+	for i := 1; i < clusterSlots; i += 2 {
+		fc.owner[i] = 1
+	}
+	fc.mu.Unlock()
+	c := newTestClusterClient(t, fc)
+
+	conn := c.Get()
+	defer conn.Close()
+	keys := []string{"a", "b", "c", "d", "e"}
+	for _, k := range keys {
+		_ = conn.Send("SET", k, "v-"+k)
+	}
+	for _, k := range keys {
+		_ = conn.Send("GET", k)
+	}
+	if err := conn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	for range keys {
+		if _, err := conn.Receive(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, k := range keys {
+		v, err := redis.String(conn.Receive())
+		if err != nil || v != "v-"+k {
+			t.Errorf("GET %s = %q, %v", k, v, err)
+		}
+	}
+	if fc.nodes[0].calls.Load() == 0 || fc.nodes[1].calls.Load() == 0 {
+		t.Errorf("calls = %d/%d, want both nodes used", fc.nodes[0].calls.Load(), fc.nodes[1].calls.Load())
+	}
+	if fc.nodes[0].calls.Load()+fc.nodes[1].calls.Load() != int64(2*len(keys)) {
+		t.Error("pipelined commands were redirected")
+	}
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/log"
+	"context"
+	"database/sql"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// replicaLagCheckInterval 默认从库延迟检查间隔
+	replicaLagCheckInterval = 5 * time.Second
+
+	// replicaLagSQL 从库回放延迟(秒)，WAL 已全部回放时视为无延迟
+	replicaLagSQL = `SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`
+)
+
+type primaryKey struct{}
+
+// readYourWrites 请求级读主库标记，force 为强制读主库，written 为本次请求已发生写操作
+type readYourWrites struct {
+	force   bool
+	written atomic.Bool
+}
+
+// WithReadYourWrites 开启请求级"读己之写"：force 为 true 时本次请求全部读主库，
+// 否则在本次请求发生写操作后，后续读操作自动切换到主库
+func WithReadYourWrites(ctx context.Context, force bool) context.Context {
+	return context.WithValue(ctx, primaryKey{}, &readYourWrites{force: force})
+}
+
+// UsePrimary 当前 context 的读操作是否应使用主库
+func UsePrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	ryw, ok := ctx.Value(primaryKey{}).(*readYourWrites)
+	return ok && (ryw.force || ryw.written.Load())
+}
+
+// lagPolicy 从库选择策略：跳过延迟超过阈值或不可用的从库，全部不可用时回退主库
+type lagPolicy struct {
+	primary gorm.ConnPool
+	maxLag  float64
+	lags    sync.Map // gorm.ConnPool -> float64 延迟秒数，不可用时为 +Inf
+}
+
+func (p *lagPolicy) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	healthy := make([]gorm.ConnPool, 0, len(pools))
+	for _, pool := range pools {
+		if p.maxLag <= 0 {
+			healthy = append(healthy, pool)
+			continue
+		}
+		if lag, ok := p.lags.Load(pool); !ok || lag.(float64) <= p.maxLag {
+			healthy = append(healthy, pool)
+		}
+	}
+	if len(healthy) == 0 {
+		return p.primary
+	}
+	return healthy[rand.Intn(len(healthy))]
+}
+
+// watch 定时检查从库延迟
+func (p *lagPolicy) watch(replicas []*sql.DB, interval time.Duration) {
+	check := func() {
+		for _, replica := range replicas {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			var lag float64
+			if err := replica.QueryRowContext(ctx, replicaLagSQL).Scan(&lag); err != nil {
+				log.Logger.Warn("check pgsql replica lag error", zap.Error(err))
+				lag = math.Inf(1)
+			} else if p.maxLag > 0 && lag > p.maxLag {
+				log.Logger.Warn("pgsql replica lag exceeds threshold", zap.Float64("lag", lag), zap.Float64("max_lag", p.maxLag))
+			}
+			cancel()
+			p.lags.Store(gorm.ConnPool(replica), lag)
+		}
+	}
+	check()
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		check()
+	}
+}
+
+// useReplicas 注册读写分离：写操作及事务内读写走主库，普通读操作走从库
+func useReplicas(db *gorm.DB, conf config.PgsqlConfig) error {
+	if len(conf.Replicas) == 0 {
+		return nil
+	}
+
+	dialectors := make([]gorm.Dialector, 0, len(conf.Replicas))
+	replicas := make([]*sql.DB, 0, len(conf.Replicas))
+	for _, dsn := range conf.Replicas {
+		sqlDB, err := sql.Open("pgx", dsn)
+		if err != nil {
+			return err
+		}
+		configurePool(sqlDB, conf.PoolConfig)
+		replicas = append(replicas, sqlDB)
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: sqlDB}))
+	}
+
+	policy := &lagPolicy{primary: db.ConnPool, maxLag: float64(conf.ReplicaMaxLag)}
+	err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   policy,
+	}))
+	if err != nil {
+		return err
+	}
+
+	// 请求要求读主库时，在读写分离选择连接前强制使用主库
+	forcePrimary := func(tx *gorm.DB) {
+		if UsePrimary(tx.Statement.Context) {
+			dbresolver.Write.ModifyStatement(tx.Statement)
+		}
+	}
+	// 写操作完成后标记本次请求已写，后续读操作走主库
+	markWritten := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Context == nil {
+			return
+		}
+		if ryw, ok := tx.Statement.Context.Value(primaryKey{}).(*readYourWrites); ok {
+			ryw.written.Store(true)
+		}
+	}
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:db_resolver").Register("bossfi:read_primary", forcePrimary); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:db_resolver").Register("bossfi:read_primary", forcePrimary); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:db_resolver").Register("bossfi:read_primary", forcePrimary); err != nil {
+		return err
+	}
+	if err := cb.Create().After("*").Register("bossfi:mark_written", markWritten); err != nil {
+		return err
+	}
+	if err := cb.Update().After("*").Register("bossfi:mark_written", markWritten); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("*").Register("bossfi:mark_written", markWritten); err != nil {
+		return err
+	}
+
+	interval := time.Duration(conf.ReplicaLagCheckInterval) * time.Second
+	if interval <= 0 {
+		interval = replicaLagCheckInterval
+	}
+	go policy.watch(replicas, interval)
+
+	log.Logger.Info("pgsql read replicas enabled", zap.Int("replicas", len(replicas)))
+	return nil
+}
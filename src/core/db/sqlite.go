@@ -0,0 +1,32 @@
+package db
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/log"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// InitSqlite 初始化SQLite，用于测试及本地开发
+func InitSqlite() *gorm.DB {
+	log.Logger.Info("Init Sqlite")
+	conf := config.Conf.Sqlite
+	path := conf.Path
+	if path == "" {
+		path = ":memory:"
+	}
+	db, err := open(SourceSqlite, sqlite.Open(path), config.PoolConfig{})
+	if err != nil {
+		panic(err)
+	}
+	// 内存数据库每个连接相互独立，限制为单连接保证数据一致
+	if path == ":memory:" {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.SetMaxOpenConns(1)
+		}
+	}
+
+	Sqlite = db
+	Register(SourceSqlite, db)
+	return db
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/log"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestInitDataSourcesSqlite(t *testing.T) {
+	log.Logger = zap.NewNop()
+	config.Conf = &config.Config{
+		DataSource: config.DataSourceConfig{Primary: SourceSqlite},
+		Sqlite:     config.SqliteConfig{Path: ":memory:"},
+	}
+	db := InitDataSources()
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if db != DB || db != Sqlite || db != MustGet(SourceSqlite) {
+		t.Fatal("sqlite is not registered as the primary data source")
+	}
+
+	// 内存数据库限制为单连接，并发查询看到同一份数据
+	if err := db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO t (id) VALUES (1), (2)").Error; err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var n int64
+			if err := db.Table("t").Count(&n).Error; err != nil || n != 2 {
+				t.Errorf("count = %d, %v, want 2", n, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
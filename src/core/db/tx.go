@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"math/rand"
+	"time"
+)
+
+const (
+	// txMaxRetries 序列化失败/死锁时事务最大重试次数
+	txMaxRetries = 3
+	// txRetryBackoff 事务重试基础退避时间
+	txRetryBackoff = 50 * time.Millisecond
+)
+
+type txKey struct{}
+
+// WithTx 在事务中执行 fn，事务通过 context 传递，fn 内使用 FromContext 或仓储 WithContext 即可加入同一事务
+//
+// ctx 中已存在事务时以保存点方式嵌套执行；最外层事务遇到序列化失败或死锁时整体重试
+func WithTx(ctx context.Context, fn func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+	if parent, ok := TxFromContext(ctx); ok {
+		// 嵌套事务，GORM 自动使用 SAVEPOINT / ROLLBACK TO
+		return parent.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(bindTx(ctx, tx))
+		})
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(bindTx(ctx, tx))
+		}, opts...)
+		if err == nil || attempt >= txMaxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		// 指数退避并加入随机抖动，避免冲突事务同时重试
+		backoff := txRetryBackoff<<attempt + time.Duration(rand.Int63n(int64(txRetryBackoff)))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// bindTx 将事务写入 context 并绑定到事务连接上
+func bindTx(ctx context.Context, tx *gorm.DB) *gorm.DB {
+	return tx.WithContext(context.WithValue(ctx, txKey{}, tx))
+}
+
+// TxFromContext 获取 context 中的事务
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// WithoutTx 返回不携带事务的 context，用于不应加入调用方事务的操作（如多个请求共享的缓存加载）
+func WithoutTx(ctx context.Context) context.Context {
+	if _, ok := TxFromContext(ctx); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, txKey{}, (*gorm.DB)(nil))
+}
+
+// FromContext 获取 context 对应的数据库连接，存在事务时返回事务连接，否则返回主数据源
+func FromContext(ctx context.Context) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return DB.WithContext(ctx)
+}
+
+// IsRetryable 是否为可重试的事务错误（序列化失败、死锁）
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 40001 serialization_failure, 40P01 deadlock_detected
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// 1213 ER_LOCK_DEADLOCK
+		return mysqlErr.Number == 1213
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/ratelimit"
+	"bossfi-backend/src/core/result"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"time"
+)
+
+// APIKeyHeader API Key 请求头
+const APIKeyHeader = "X-API-Key"
+
+const (
+	defaultAuthFailLimit  = 10
+	defaultAuthFailPeriod = time.Minute
+)
+
+// ApiKeyMiddleware API Key 认证中间件：请求携带 X-API-Key 时校验并将认证主体写入请求 context，
+// key 无效返回 Unauthorized(HTTP 401)；未携带时不处理，由路由上的 RequirePermission 决定是否需要认证
+//
+// 开启 [rate_limit] 时按客户端IP统计校验失败次数，超过 auth_fail_limit 后不再校验直接返回 TooManyRequests(HTTP 429)，
+// 有效 key 的校验结果有缓存，只有无效 key 会查库
+func ApiKeyMiddleware() gin.HandlerFunc {
+	var limiter *ratelimit.Limiter
+	failRule := ratelimit.Rule{Limit: defaultAuthFailLimit, Period: defaultAuthFailPeriod}
+	if conf := config.Conf.RateLimit; conf.Enable {
+		limiter = ratelimit.New(db.Redis, config.Conf.App.Name)
+		if conf.AuthFailLimit > 0 {
+			failRule.Limit = conf.AuthFailLimit
+		}
+		if conf.AuthFailPeriod > 0 {
+			failRule.Period = time.Duration(conf.AuthFailPeriod) * time.Second
+		}
+	}
+
+	return func(c *gin.Context) {
+		plain := c.GetHeader(APIKeyHeader)
+		if plain == "" {
+			c.Next()
+			return
+		}
+
+		// 失败次数只在校验失败时消耗，先检查该IP是否已超限；Redis 不可用时放行
+		// ClientIP 只采用 [app] trusted_proxies 转发的 X-Forwarded-For，否则为连接的对端地址，轮换请求头无法绕过
+		failKey := "api_key_fail:ip:" + c.ClientIP()
+		if limiter != nil {
+			res, err := limiter.Check(c.Request.Context(), failKey, failRule)
+			if err != nil {
+				log.Logger.Warn("rate limit error", zap.Error(err))
+			} else if !res.Allowed {
+				c.Header(RetryAfterHeader, ceilSeconds(res.RetryAfter))
+				result.Error(c, result.TooManyRequests)
+				c.Abort()
+				return
+			}
+		}
+
+		key, err := auth.VerifyApiKey(c.Request.Context(), plain)
+		if err != nil {
+			if !errors.Is(err, auth.ErrInvalidApiKey) {
+				log.Logger.Error("verify api key error", zap.Error(err))
+				result.Error(c, result.SystemError)
+			} else {
+				if limiter != nil {
+					if _, err := limiter.Allow(c.Request.Context(), failKey, failRule); err != nil {
+						log.Logger.Warn("rate limit error", zap.Error(err))
+					}
+				}
+				result.Error(c, result.Unauthorized)
+			}
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), key.Principal()))
+		c.Next()
+	}
+}
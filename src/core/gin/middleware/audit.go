@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditMiddleware 将请求方法、路由、客户端IP及请求ID写入请求 context，审计日志据此记录写操作来源，操作者取自认证主体
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := audit.WithRequest(c.Request.Context(), &audit.Request{
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Ip:        c.ClientIP(),
+			RequestId: c.GetString(RequestIdKey),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
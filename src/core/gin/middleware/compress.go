@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/config"
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 响应压缩编码
+const (
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+const defaultCompressMinSize = 1024
+
+// compressibleTypes 可压缩的响应类型，图片、压缩包等已压缩的内容不再压缩
+var compressibleTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/x-ndjson",
+	"image/svg+xml",
+}
+
+// encoder 压缩编码器，Reset 后复用
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressWriter 缓冲响应体，达到 MinSize 时开始压缩，响应结束时不足 MinSize 则原样写出
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	pool     *sync.Pool
+	buf      []byte
+	enc      encoder
+	started  bool
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) >= w.minSize {
+			if err := w.start(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(b), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written 缓冲中的响应同样视为已写入，避免超时、异常恢复等中间件重复写响应
+func (w *compressWriter) Written() bool {
+	return w.started || len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush 流式响应立即开始输出，不再等待 MinSize
+func (w *compressWriter) Flush() {
+	if !w.started {
+		if err := w.start(true); err != nil {
+			return
+		}
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start 确定是否压缩并写出缓冲的数据，写出前设置响应头
+func (w *compressWriter) start(compress bool) error {
+	w.started = true
+	h := w.ResponseWriter.Header()
+	if compress && h.Get("Content-Encoding") == "" && bodyAllowed(w.Status()) && compressible(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		w.enc = w.pool.Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+	data := w.buf
+	w.buf = nil
+	if len(data) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(data)
+	} else {
+		_, err = w.ResponseWriter.Write(data)
+	}
+	return err
+}
+
+// finish 响应结束：写出未达到 MinSize 的缓冲数据或结束压缩，之后的写入（如异常恢复的错误响应）不再压缩
+func (w *compressWriter) finish() {
+	if !w.started {
+		if len(w.buf) == 0 {
+			return
+		}
+		_ = w.start(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(io.Discard)
+		w.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
+
+// CompressMiddleware 响应压缩中间件，按请求头 Accept-Encoding 协商 br/gzip，
+// 响应体达到 [compress] min_size 且为文本、JSON等可压缩类型时压缩，并设置 Vary: Accept-Encoding
+//
+// 需注册在日志、幂等等读取响应体的中间件之前，使其记录未压缩的响应
+func CompressMiddleware() gin.HandlerFunc {
+	conf := config.Conf.Compress
+	if !conf.Enable {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	minSize := conf.MinSize
+	if minSize <= 0 {
+		minSize = defaultCompressMinSize
+	}
+	configured := conf.Encodings
+	if len(configured) == 0 {
+		configured = []string{EncodingBrotli, EncodingGzip}
+	}
+	// 忽略不支持的编码
+	encodings := make([]string, 0, len(configured))
+	pools := make(map[string]*sync.Pool, len(configured))
+	for _, e := range configured {
+		e = strings.ToLower(e)
+		if pool := encoderPool(e, conf.Level); pool != nil && pools[e] == nil {
+			encodings = append(encodings, e)
+			pools[e] = pool
+		}
+	}
+
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), encodings)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize, pool: pools[encoding]}
+		c.Writer = w
+		// 处理函数 panic 时同样写出已缓冲的数据，异常恢复中间件据此判断是否已写响应
+		defer w.finish()
+		c.Next()
+	}
+}
+
+// encoderPool 按编码创建编码器池，不支持的编码返回 nil
+func encoderPool(encoding string, level int) *sync.Pool {
+	switch encoding {
+	case EncodingBrotli:
+		if level <= 0 || level > brotli.BestCompression {
+			level = 4
+		}
+		return &sync.Pool{New: func() interface{} {
+			return brotli.NewWriterLevel(io.Discard, level)
+		}}
+	case EncodingGzip:
+		if level <= 0 || level > gzip.BestCompression {
+			level = gzip.DefaultCompression
+		}
+		return &sync.Pool{New: func() interface{} {
+			w, _ := gzip.NewWriterLevel(io.Discard, level)
+			return w
+		}}
+	}
+	return nil
+}
+
+// negotiateEncoding 按 Accept-Encoding 的 q 值选择编码，q 值相同时按 supported 顺序，没有可用编码返回空
+func negotiateEncoding(header string, supported []string) string {
+	if header == "" {
+		return ""
+	}
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	candidates := make([]string, 0, len(supported))
+	for _, e := range supported {
+		q, ok := weights[e]
+		if !ok {
+			q, ok = weights["*"]
+		}
+		if ok && q > 0 {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return weightOf(weights, candidates[i]) > weightOf(weights, candidates[j])
+	})
+	return candidates[0]
+}
+
+func weightOf(weights map[string]float64, encoding string) float64 {
+	if q, ok := weights[encoding]; ok {
+		return q
+	}
+	return weights["*"]
+}
+
+// compressible 响应类型是否可压缩，未设置类型时不压缩
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, t := range compressibleTypes {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return strings.Contains(contentType, "+json") || strings.Contains(contentType, "+xml")
+}
+
+// bodyAllowed 状态码是否允许响应体
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
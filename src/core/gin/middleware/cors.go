@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/log"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"strings"
+	"time"
+)
+
+var (
+	defaultCorsMethods       = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"}
+	defaultCorsHeaders       = []string{"Origin", "Content-Length", "Content-Type", "X-CSRF-Token", "Authorization", "AccessToken", "Token"}
+	defaultCorsExposeHeaders = []string{"Content-Length", "Content-Type", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "X-GW-Error-Code", "X-GW-Error-Message"}
+)
+
+// CorsMiddleware 跨域中间件，按 [cors] 配置允许的来源，来源支持通配子域名 https://*.example.com，
+// "*" 允许全部来源（仅用于开发环境）
+//
+// 非 dev 环境未配置 allow_origins 时拒绝启动；允许全部来源时响应 Access-Control-Allow-Origin: *，且不允许携带凭证
+//
+// 框架使用的请求头（X-Read-Primary、X-API-Key、session_id、Idempotency-Key 等）及响应头（RateLimit-* 等）始终允许
+func CorsMiddleware() gin.HandlerFunc {
+	conf := config.Conf.Cors
+
+	origins := conf.AllowOrigins
+	if len(origins) == 0 {
+		if config.Conf.App.Env != "dev" {
+			panic("cors allow_origins must be configured outside dev environment")
+		}
+		log.Logger.Warn("cors allow_origins not configured, allowing all origins in dev environment")
+		origins = []string{"*"}
+	}
+
+	maxAge := time.Hour
+	if conf.MaxAge > 0 {
+		maxAge = time.Duration(conf.MaxAge) * time.Second
+	}
+
+	corsConf := cors.Config{
+		AllowMethods:     orDefault(conf.AllowMethods, defaultCorsMethods),
+		AllowHeaders:     append(orDefault(conf.AllowHeaders, defaultCorsHeaders), ReadPrimaryHeader, APIKeyHeader, SessionHeader, IdempotencyKeyHeader, RequestIdHeader, "If-None-Match"),
+		ExposeHeaders:    append(orDefault(conf.ExposeHeaders, defaultCorsExposeHeaders), RateLimitLimitHeader, RateLimitRemainingHeader, RateLimitResetHeader, RateLimitPolicyHeader, RetryAfterHeader, IdempotentReplayedHeader, RequestIdHeader, "ETag"),
+		AllowCredentials: conf.AllowCredentials != nil && *conf.AllowCredentials,
+		MaxAge:           maxAge,
+	}
+	if allowAll(origins) {
+		// 浏览器不接受 * 与凭证同时使用，回显任意来源并允许凭证会使任意网站可携带用户凭证调用接口
+		if corsConf.AllowCredentials {
+			log.Logger.Warn("cors allow_credentials is ignored when all origins are allowed")
+		}
+		corsConf.AllowAllOrigins = true
+		corsConf.AllowCredentials = false
+	} else {
+		corsConf.AllowOriginFunc = originMatcher(origins)
+	}
+	return cors.New(corsConf)
+}
+
+// allowAll 来源中是否包含 "*"
+func allowAll(origins []string) bool {
+	for _, o := range origins {
+		if strings.TrimSpace(o) == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// originMatcher 来源匹配：精确匹配或通配子域名（不匹配主域名本身）
+func originMatcher(patterns []string) func(origin string) bool {
+	exact := map[string]bool{}
+	var wildcards [][2]string // scheme:// 与 .domain[:port]
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(p), "/"))
+		if i := strings.Index(p, "://*."); i >= 0 {
+			wildcards = append(wildcards, [2]string{p[:i+3], p[i+4:]})
+			continue
+		}
+		exact[p] = true
+	}
+
+	return func(origin string) bool {
+		origin = strings.ToLower(origin)
+		if exact[origin] {
+			return true
+		}
+		for _, w := range wildcards {
+			if !strings.HasPrefix(origin, w[0]) || !strings.HasSuffix(origin, w[1]) {
+				continue
+			}
+			// 子域名部分不能为空且不能包含端口或路径
+			sub := origin[len(w[0]) : len(origin)-len(w[1])]
+			if sub != "" && !strings.ContainsAny(sub, ":/") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func orDefault(values, defaults []string) []string {
+	if len(values) == 0 {
+		values = defaults
+	}
+	return append([]string(nil), values...)
+}
@@ -12,6 +12,17 @@ import (
 	"time"
 )
 
+// skipResponseLogKey gin 上下文中不记录响应体的标记，由 NoResponseLog 设置
+const skipResponseLogKey = "skip_response_log"
+
+// NoResponseLog 路由中间件：请求日志不记录响应体，用于返回密钥等敏感数据的接口（如创建 API Key 返回明文、钱包登录返回会话 token）
+func NoResponseLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(skipResponseLogKey, true)
+		c.Next()
+	}
+}
+
 type BodyLogWriter struct {
 	gin.ResponseWriter
 	body *bytes.Buffer
@@ -26,6 +37,7 @@ func (w BodyLogWriter) WriteString(s string) (int, error) {
 	return w.ResponseWriter.WriteString(s)
 }
 
+// HttpLogMiddleware 请求日志中间件：查询参数及 JSON 请求体按字段名脱敏（同错误上报），非 JSON 请求体及会话 token 不记录
 func HttpLogMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取原始请求路径和查询参数(避免被其他中间件修改)
@@ -53,6 +65,9 @@ func HttpLogMiddleware() gin.HandlerFunc {
 
 		// 获取响应体
 		responseBody := bodyLogWriter.body.Bytes()
+		if c.GetBool(skipResponseLogKey) {
+			responseBody = []byte(redacted)
+		}
 		if len(c.Errors) > 0 {
 			// 如果有错误,记录错误信息
 			for _, e := range c.Errors.Errors() {
@@ -66,17 +81,26 @@ func HttpLogMiddleware() gin.HandlerFunc {
 				zap.Int("status", c.Writer.Status()),
 				zap.String("method", c.Request.Method),
 				zap.String("function", c.HandlerName()),
+				zap.String("request_id", c.GetString(RequestIdKey)),
 				zap.String("path", path),
-				zap.String("query", query),
+				zap.String("query", redactQuery(query)),
 				zap.String("ip", c.ClientIP()),
 				zap.String("user-agent", c.Request.UserAgent()),
-				zap.String("token", c.Request.Header.Get("session_id")),
+				zap.String("token", redactHeader(c.Request.Header.Get(SessionHeader))),
 				zap.String("content-type", c.Request.Header.Get("Content-Type")),
 				zap.Float64("latency", latency),
-				zap.String("request", string(requestBody)),
+				zap.String("request", redactBody(requestBody)),
 				zap.String("response", string(responseBody)),
 			}
 			log.Logger.Info("Go-End", fields...)
 		}
 	}
 }
+
+// redactHeader 敏感请求头只记录是否携带
+func redactHeader(v string) string {
+	if v == "" {
+		return ""
+	}
+	return redacted
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/idempotency"
+	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/result"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader 幂等键请求头
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 响应为重放时返回该响应头
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+const (
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyLockTTL = time.Minute
+	// maxIdempotencyKeyLen 幂等键最大长度
+	maxIdempotencyKeyLen = 255
+)
+
+// idempotentMethods 支持幂等键的请求方法
+var idempotentMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// idempotencyWriter 记录响应体用于保存
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware 幂等中间件：携带 Idempotency-Key 的 POST/PUT/PATCH/DELETE 请求，
+// 最终响应按调用方及幂等键保存在 Redis 中，ttl 内重复请求直接重放响应（响应头 Idempotent-Replayed: true）
+//
+// 相同幂等键的请求体或路径不同时返回 IdempotencyKeyMismatch(HTTP 422)，上一个请求仍在处理中时返回 IdempotencyInProgress(HTTP 409)；
+// 系统错误（5xx 或 2 开头的业务码）及 401/403/429 不保存，客户端可使用同一幂等键重试；Redis 不可用时放行
+func IdempotencyMiddleware() gin.HandlerFunc {
+	conf := config.Conf.Idempotency
+	if !conf.Enable {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	store := idempotency.New(db.Redis, config.Conf.App.Name)
+	ttl, lockTTL := defaultIdempotencyTTL, defaultIdempotencyLockTTL
+	if conf.TTL > 0 {
+		ttl = time.Duration(conf.TTL) * time.Second
+	}
+	if conf.LockTTL > 0 {
+		lockTTL = time.Duration(conf.LockTTL) * time.Second
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !idempotentMethods[c.Request.Method] {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			result.Error(c, result.InvalidParameter)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			result.Error(c, result.InvalidParameter)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scoped := idempotencyScope(c, key)
+		fingerprint := requestFingerprint(c, body)
+		if replayIdempotent(c, store, scoped, fingerprint) {
+			return
+		}
+
+		unlock, err := store.Lock(ctx, scoped, lockTTL)
+		if errors.Is(err, idempotency.ErrInProgress) {
+			result.Error(c, result.IdempotencyInProgress)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			log.Logger.Warn("idempotency lock error", zap.Error(err))
+			c.Next()
+			return
+		}
+		// 处理函数 panic 时同样释放锁，允许客户端重试
+		defer func() {
+			_ = unlock(context.WithoutCancel(ctx))
+		}()
+		// 等待锁期间上一个请求可能已完成
+		if replayIdempotent(c, store, scoped, fingerprint) {
+			return
+		}
+
+		w := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if !w.Written() || !storableResponse(w.Status(), w.body.Bytes()) {
+			return
+		}
+		rec := &idempotency.Record{
+			Fingerprint: fingerprint,
+			Status:      w.Status(),
+			Header:      http.Header{"Content-Type": w.Header().Values("Content-Type")},
+			Body:        w.body.Bytes(),
+		}
+		// 请求 context 可能已超时，保存不受其影响
+		if err := store.Save(context.WithoutCancel(ctx), scoped, rec, ttl); err != nil {
+			log.Logger.Warn("idempotency save error", zap.Error(err))
+		}
+	}
+}
+
+// replayIdempotent 幂等键已有完成的记录时重放响应，请求不同时拒绝，返回是否已处理
+func replayIdempotent(c *gin.Context, store *idempotency.Store, key, fingerprint string) bool {
+	rec, err := store.Get(c.Request.Context(), key)
+	if errors.Is(err, idempotency.ErrNotFound) {
+		return false
+	}
+	if err != nil {
+		log.Logger.Warn("idempotency get error", zap.Error(err))
+		return false
+	}
+
+	if rec.Fingerprint != fingerprint {
+		result.Error(c, result.IdempotencyKeyMismatch)
+		c.Abort()
+		return true
+	}
+	for k, v := range rec.Header {
+		c.Writer.Header()[k] = v
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(rec.Status, rec.Header.Get("Content-Type"), rec.Body)
+	c.Abort()
+	return true
+}
+
+// idempotencyScope 幂等键按调用方隔离：已认证时为认证主体，否则为客户端IP
+func idempotencyScope(c *gin.Context, key string) string {
+	scope := "ip:" + c.ClientIP()
+	if p := auth.FromContext(c.Request.Context()); p != nil {
+		scope = p.Type + ":" + strings.ToLower(p.Subject)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return scope + ":" + hex.EncodeToString(sum[:])
+}
+
+// requestFingerprint 请求指纹：方法、路径（含查询参数）及请求体的哈希
+func requestFingerprint(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + "\n" + c.Request.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// storableResponse 是否保存响应：5xx 及系统错误业务码视为临时失败，不保存；
+// 401/403/429 取决于认证、权限及限流状态而非请求本身，同样不保存，避免修正后仍重放失败响应
+func storableResponse(status int, body []byte) bool {
+	switch {
+	case status >= http.StatusInternalServerError,
+		status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		return false
+	}
+	var resp struct {
+		Code *int `json:"code"`
+	}
+	if json.Unmarshal(body, &resp) == nil && resp.Code != nil && *resp.Code >= result.SystemError {
+		return false
+	}
+	return true
+}
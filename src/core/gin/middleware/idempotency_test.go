@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/idempotency"
+	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/result"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomodule/redigo/redis"
+	"go.uber.org/zap"
+)
+
+// memoryConn 只支持 GET/SET 的内存 Redis 连接，用于测试幂等记录的读写
+type memoryConn struct {
+	data map[string][]byte
+}
+
+func (c *memoryConn) Close() error { return nil }
+func (c *memoryConn) Err() error   { return nil }
+
+func (c *memoryConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	switch strings.ToUpper(cmd) {
+	case "GET":
+		if v, ok := c.data[args[0].(string)]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case "SET":
+		c.data[args[0].(string)] = args[1].([]byte)
+		return "OK", nil
+	}
+	return nil, fmt.Errorf("unsupported command %s", cmd)
+}
+
+func (c *memoryConn) DoContext(_ context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return c.Do(cmd, args...)
+}
+
+func (c *memoryConn) Send(string, ...interface{}) error { return errors.New("not supported") }
+func (c *memoryConn) Flush() error                      { return errors.New("not supported") }
+func (c *memoryConn) Receive() (interface{}, error)     { return nil, errors.New("not supported") }
+func (c *memoryConn) ReceiveContext(context.Context) (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+type memoryPool struct {
+	conn *memoryConn
+}
+
+func (p memoryPool) GetContext(context.Context) (redis.Conn, error) {
+	return p.conn, nil
+}
+
+func newTestContext(method, target, body string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	c.Request.RemoteAddr = "10.0.0.1:1234"
+	return c
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	fingerprint := func(method, target, body string) string {
+		return requestFingerprint(newTestContext(method, target, body), []byte(body))
+	}
+	base := fingerprint("POST", "/api/v1/demo?a=1", `{"x":1}`)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		wantSameAsBase bool
+	}{
+		{"same request", "POST", "/api/v1/demo?a=1", `{"x":1}`, true},
+		{"different body", "POST", "/api/v1/demo?a=1", `{"x":2}`, false},
+		{"different query", "POST", "/api/v1/demo?a=2", `{"x":1}`, false},
+		{"different path", "POST", "/api/v1/other?a=1", `{"x":1}`, false},
+		{"different method", "PUT", "/api/v1/demo?a=1", `{"x":1}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(tt.method, tt.target, tt.body) == base; got != tt.wantSameAsBase {
+				t.Errorf("fingerprint equal = %v, want %v", got, tt.wantSameAsBase)
+			}
+		})
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	scope := func(p *auth.Principal, key string) string {
+		c := newTestContext("POST", "/", "")
+		if p != nil {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		}
+		return idempotencyScope(c, key)
+	}
+	wallet := &auth.Principal{Type: auth.PrincipalWallet, Subject: "0xABC"}
+
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"same principal and key", scope(wallet, "k"), scope(&auth.Principal{Type: auth.PrincipalWallet, Subject: "0xabc"}, "k"), true},
+		{"different key", scope(wallet, "k"), scope(wallet, "k2"), false},
+		{"different principal", scope(wallet, "k"), scope(&auth.Principal{Type: auth.PrincipalWallet, Subject: "0xdef"}, "k"), false},
+		{"principal type", scope(&auth.Principal{Type: auth.PrincipalApiKey, Subject: "1"}, "k"),
+			scope(&auth.Principal{Type: auth.PrincipalWallet, Subject: "1"}, "k"), false},
+		{"anonymous vs principal", scope(nil, "k"), scope(wallet, "k"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a == tt.b; got != tt.same {
+				t.Errorf("%s == %s is %v, want %v", tt.a, tt.b, got, tt.same)
+			}
+		})
+	}
+	if s := scope(nil, "k"); !strings.HasPrefix(s, "ip:10.0.0.1:") {
+		t.Errorf("anonymous scope = %s, want client ip", s)
+	}
+}
+
+func TestStorableResponse(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{http.StatusOK, `{"code":0,"msg":"OK"}`, true},
+		{http.StatusOK, fmt.Sprintf(`{"code":%d}`, result.InvalidParameter), true},
+		{http.StatusConflict, fmt.Sprintf(`{"code":%d}`, result.VersionConflict), true},
+		{http.StatusOK, fmt.Sprintf(`{"code":%d}`, result.DBCreateFailed), false},
+		{http.StatusOK, fmt.Sprintf(`{"code":%d}`, result.SystemError), false},
+		{http.StatusInternalServerError, `{"code":0}`, false},
+		{http.StatusGatewayTimeout, ``, false},
+		{http.StatusUnauthorized, `{}`, false},
+		{http.StatusForbidden, `{}`, false},
+		{http.StatusTooManyRequests, `{}`, false},
+		{http.StatusNoContent, ``, true},
+		{http.StatusOK, `not json`, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d %s", tt.status, tt.body), func(t *testing.T) {
+			if got := storableResponse(tt.status, []byte(tt.body)); got != tt.want {
+				t.Errorf("storableResponse = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayIdempotent(t *testing.T) {
+	log.Logger = zap.NewNop()
+	store := idempotency.New(memoryPool{conn: &memoryConn{data: map[string][]byte{}}}, "test")
+	rec := &idempotency.Record{
+		Fingerprint: "fp",
+		Status:      http.StatusCreated,
+		Header:      http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+		Body:        []byte(`{"code":0,"data":{"id":1}}`),
+	}
+	if err := store.Save(context.Background(), "saved", rec, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		key         string
+		fingerprint string
+		handled     bool
+		status      int
+		body        string
+		replayed    bool
+	}{
+		{"no record", "missing", "fp", false, 0, "", false},
+		{"replay", "saved", "fp", true, http.StatusCreated, `{"code":0,"data":{"id":1}}`, true},
+		{"different request", "saved", "other", true, http.StatusUnprocessableEntity, fmt.Sprintf(`"code":%d`, result.IdempotencyKeyMismatch), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/", nil)
+
+			if got := replayIdempotent(c, store, tt.key, tt.fingerprint); got != tt.handled {
+				t.Fatalf("replayIdempotent = %v, want %v", got, tt.handled)
+			}
+			if !tt.handled {
+				return
+			}
+			if !c.IsAborted() {
+				t.Error("request not aborted")
+			}
+			if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("response = %d %s, want %d %s", w.Code, w.Body.String(), tt.status, tt.body)
+			}
+			if got := w.Header().Get(IdempotentReplayedHeader) == "true"; got != tt.replayed {
+				t.Errorf("%s header = %v, want %v", IdempotentReplayedHeader, got, tt.replayed)
+			}
+			if tt.replayed && w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type = %q", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/ratelimit"
+	"bossfi-backend/src/core/result"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 限流响应头
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// 限流客户端标识
+const (
+	rateLimitByAPIKey  = "api_key"
+	rateLimitByAddress = "address"
+	rateLimitByIP      = "ip"
+)
+
+// rateLimitCheck 一条生效的限流规则
+type rateLimitCheck struct {
+	scope string
+	rule  ratelimit.Rule
+}
+
+// RateLimitMiddleware 限流中间件，按 [rate_limit] 配置对每个客户端限流，超限返回 TooManyRequests(HTTP 429)
+//
+// 路由单独配置的规则与 API Key 上的限流规则同时生效（任一超限即拒绝），路由未配置时 API Key 的规则替代默认规则；
+// Redis 不可用时放行，避免限流组件故障导致服务不可用
+func RateLimitMiddleware() gin.HandlerFunc {
+	conf := config.Conf.RateLimit
+	if !conf.Enable {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	limiter := ratelimit.New(db.Redis, config.Conf.App.Name)
+	defaultRule := ratelimit.Rule{Limit: conf.Limit, Period: time.Duration(conf.Period) * time.Second}
+	routes := make(map[string]ratelimit.Rule, len(conf.Routes))
+	for _, r := range conf.Routes {
+		routes[routeKey(r.Method, r.Path)] = ratelimit.Rule{Limit: r.Limit, Period: time.Duration(r.Period) * time.Second}
+	}
+	keyBy := conf.KeyBy
+	if len(keyBy) == 0 {
+		keyBy = []string{rateLimitByAPIKey, rateLimitByAddress, rateLimitByIP}
+	}
+
+	return func(c *gin.Context) {
+		var checks []rateLimitCheck
+		for _, key := range []string{routeKey(c.Request.Method, c.FullPath()), routeKey("", c.FullPath())} {
+			if r, ok := routes[key]; ok {
+				checks = append(checks, rateLimitCheck{scope: key, rule: r})
+				break
+			}
+		}
+		if p := auth.FromContext(c.Request.Context()); p != nil && p.RateLimit != nil {
+			checks = append(checks, rateLimitCheck{scope: "principal", rule: *p.RateLimit})
+		}
+		if len(checks) == 0 {
+			checks = append(checks, rateLimitCheck{scope: "default", rule: defaultRule})
+		}
+
+		// 先检查全部规则（不消耗配额），任一规则超限即拒绝，被拒绝的请求不消耗其他规则的配额；
+		// 全部通过后再依次消耗配额，响应头返回剩余配额最少（或拒绝）的规则
+		client := rateLimitClient(c, keyBy)
+		var allowed []rateLimitCheck
+		for _, check := range checks {
+			if check.rule.Limit <= 0 || check.rule.Period <= 0 {
+				continue
+			}
+			r, err := limiter.Check(c.Request.Context(), check.scope+":"+client, check.rule)
+			if err != nil {
+				log.Logger.Warn("rate limit error", zap.Error(err))
+				continue
+			}
+			if !r.Allowed {
+				rejectRateLimit(c, r, check.rule)
+				return
+			}
+			allowed = append(allowed, check)
+		}
+
+		var res *ratelimit.Result
+		var rule ratelimit.Rule
+		for _, check := range allowed {
+			r, err := limiter.Allow(c.Request.Context(), check.scope+":"+client, check.rule)
+			if err != nil {
+				log.Logger.Warn("rate limit error", zap.Error(err))
+				continue
+			}
+			// 检查与消耗之间配额可能被并发请求用完
+			if !r.Allowed {
+				rejectRateLimit(c, r, check.rule)
+				return
+			}
+			if res == nil || r.Remaining < res.Remaining {
+				res, rule = r, check.rule
+			}
+		}
+		if res == nil {
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, res, rule)
+		c.Next()
+	}
+}
+
+// rejectRateLimit 返回 TooManyRequests 及超限规则的响应头
+func rejectRateLimit(c *gin.Context, res *ratelimit.Result, rule ratelimit.Rule) {
+	setRateLimitHeaders(c, res, rule)
+	c.Header(RetryAfterHeader, ceilSeconds(res.RetryAfter))
+	result.Error(c, result.TooManyRequests)
+	c.Abort()
+}
+
+func setRateLimitHeaders(c *gin.Context, res *ratelimit.Result, rule ratelimit.Rule) {
+	c.Header(RateLimitLimitHeader, strconv.Itoa(res.Limit))
+	c.Header(RateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+	c.Header(RateLimitResetHeader, ceilSeconds(res.Reset))
+	c.Header(RateLimitPolicyHeader, fmt.Sprintf("%d;w=%d", rule.Limit, int(rule.Period.Seconds())))
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// rateLimitClient 客户端标识，按 keyBy 顺序取第一个存在的，API Key 及钱包地址取自认证主体
+func rateLimitClient(c *gin.Context, keyBy []string) string {
+	for _, by := range keyBy {
+		switch by {
+		case rateLimitByAPIKey:
+			if p := auth.FromContext(c.Request.Context()); p != nil && p.Type == auth.PrincipalApiKey {
+				return "key:" + p.Subject
+			}
+		case rateLimitByAddress:
+			if p := auth.FromContext(c.Request.Context()); p != nil && p.Type == auth.PrincipalWallet {
+				return "addr:" + strings.ToLower(p.Subject)
+			}
+		case rateLimitByIP:
+			return "ip:" + c.ClientIP()
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
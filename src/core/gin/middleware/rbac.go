@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/result"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequirePermission 要求已认证且拥有全部权限（API Key 授权范围或所绑定角色的权限），
+// 未认证返回 Unauthorized(HTTP 401)，缺少权限返回 Forbidden(HTTP 403)
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := auth.FromContext(c.Request.Context())
+		if p == nil {
+			result.Error(c, result.Unauthorized)
+			c.Abort()
+			return
+		}
+		authorize(c, p, permissions)
+	}
+}
+
+// RequireRole 要求已认证且拥有任一角色（已绑定，或有效权限覆盖该角色的全部权限），未认证返回 Unauthorized，缺少角色返回 Forbidden
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := auth.FromContext(c.Request.Context())
+		if p == nil {
+			result.Error(c, result.Unauthorized)
+			c.Abort()
+			return
+		}
+		ok, err := auth.HasRole(c.Request.Context(), p, roles...)
+		if err != nil {
+			log.Logger.Error("load roles error", zap.String("subject", p.Subject), zap.Error(err))
+			result.Error(c, result.SystemError)
+			c.Abort()
+			return
+		}
+		if !ok {
+			result.Error(c, result.Forbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ApiKeyScope 仅限制 API Key 调用方的权限，匿名及钱包登录请求直接放行，用于开放接口
+func ApiKeyScope(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := auth.FromContext(c.Request.Context())
+		if p == nil || p.Type != auth.PrincipalApiKey {
+			c.Next()
+			return
+		}
+		authorize(c, p, permissions)
+	}
+}
+
+func authorize(c *gin.Context, p *auth.Principal, permissions []string) {
+	ok, err := auth.Authorize(c.Request.Context(), p, permissions...)
+	if err != nil {
+		log.Logger.Error("load permissions error", zap.String("subject", p.Subject), zap.Error(err))
+		result.Error(c, result.SystemError)
+		c.Abort()
+		return
+	}
+	if !ok {
+		result.Error(c, result.Forbidden)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
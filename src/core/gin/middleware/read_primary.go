@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/db"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// ReadPrimaryHeader 请求头，值为 true/1 时本次请求的读操作强制走主库
+const ReadPrimaryHeader = "X-Read-Primary"
+
+// ReadYourWritesMiddleware 读己之写：本次请求发生写操作后后续读操作走主库，
+// 客户端在写入后立即查询时可通过 X-Read-Primary 请求头强制读主库
+func ReadYourWritesMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		force := strings.EqualFold(c.GetHeader(ReadPrimaryHeader), "true") || c.GetHeader(ReadPrimaryHeader) == "1"
+		c.Request = c.Request.WithContext(db.WithReadYourWrites(c.Request.Context(), force))
+		c.Next()
+	}
+}
@@ -1,132 +1,191 @@
 package middleware
 
 import (
+	"bossfi-backend/src/core/config"
 	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/report"
 	"bossfi-backend/src/core/result"
 	"bytes"
+	"encoding/json"
+	"errors"
+	"expvar"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 	"io"
-	"io/ioutil"
 	"net/http"
-	"net/http/httputil"
-	"runtime"
+	"net/url"
+	"os"
+	"regexp"
+	"syscall"
 )
 
-var (
-	dunno     = []byte("???")
-	centerDot = []byte("·")
-	dot       = []byte(".")
-	slash     = []byte("/")
+const (
+	// maxReportBody 上报的请求体最大长度
+	maxReportBody = 4096
+	// redacted 脱敏后的值
+	redacted = "[REDACTED]"
 )
 
+// panics 按路由统计 panic 次数，通过 pprof 端口的 /debug/vars 查看
+var panics = expvar.NewMap("http_panics")
+
+// sensitiveHeaders 上报时脱敏的请求头，名称包含 sensitiveField 的请求头同样脱敏
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+}
+
+// sensitiveField 上报及请求日志中脱敏的请求头、JSON 字段及查询参数
+var sensitiveField = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[_-]?key|signature|private|mnemonic|seed|session)`)
+
+// RecoverPanicMiddleware 恢复中间件：处理函数 panic 时记录结构化日志（栈帧、请求ID、脱敏后的请求），
+// 累加 panic 计数，上报到 report.Default，返回 SystemError 并中止后续处理
+//
+// 栈帧只使用程序计数器解析，不读取源码文件；客户端已断开（broken pipe）时只记录日志
 func RecoverPanicMiddleware() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
+	return func(c *gin.Context) {
+		// 处理函数会读取请求体，先保留开头部分用于上报
+		body := peekBody(c.Request)
 		defer func() {
-			if err := recover(); err != nil {
-				log.Logger.Error(fmt.Sprintf("[Recovery] panic recovered, request:%s%v [## stack:]:\n%s", dumpRequest(ctx.Request), err, dumpStack(3)))
-				result.Error(ctx, result.SystemError)
+			err := recover()
+			if err == nil {
+				return
+			}
+			if brokenPipe(err) {
+				log.Logger.Warn("connection closed by client", zap.String("request_id", c.GetString(RequestIdKey)), zap.Any("error", err))
+				c.Abort()
+				return
 			}
-		}()
 
-		ctx.Next()
-	}
-}
+			route := c.FullPath()
+			if route == "" {
+				route = "unmatched"
+			}
+			panics.Add(route, 1)
 
-// dumpRequest 格式化请求样式
-func dumpRequest(req *http.Request) string {
-	var dup io.ReadCloser
-	req.Body, dup = dupReadCloser(req.Body)
+			// 跳过 defer 函数本身
+			stack := report.Callers(1)
+			event := report.NewEvent(report.LevelFatal, fmt.Sprint(err))
+			event.Environment = config.Conf.App.Env
+			event.ServerName, _ = os.Hostname()
+			event.Exception = []report.Exception{{Type: fmt.Sprintf("%T", err), Value: fmt.Sprint(err), Stacktrace: stack}}
+			event.Request = reportRequest(c.Request, body)
+			event.Tags["request_id"] = c.GetString(RequestIdKey)
+			event.Tags["route"] = route
 
-	var b bytes.Buffer
-	var err error
+			log.Logger.Error("panic recovered",
+				zap.String("event_id", event.EventID),
+				zap.String("request_id", c.GetString(RequestIdKey)),
+				zap.String("route", route),
+				zap.Any("error", err),
+				zap.Any("request", event.Request),
+				zap.Any("frames", stack.Frames))
+			if reportErr := report.Default.Report(c.Request.Context(), event); reportErr != nil {
+				log.Logger.Error("report panic error", zap.Error(reportErr))
+			}
 
-	reqURI := req.RequestURI
-	if reqURI == "" {
-		reqURI = req.URL.RequestURI()
-	}
+			if !c.Writer.Written() {
+				result.Error(c, result.SystemError)
+			}
+			c.Abort()
+		}()
 
-	_, _ = fmt.Fprintf(&b, "%s %s HTTP/%d.%d\n", req.Method, reqURI, req.ProtoMajor, req.ProtoMinor)
-	chunked := len(req.TransferEncoding) > 0 && req.TransferEncoding[0] == "chunked"
-	if req.Body != nil {
-		var n int64
-		var dest io.Writer = &b
-		if chunked {
-			dest = httputil.NewChunkedWriter(dest)
-		}
-		n, err = io.Copy(dest, req.Body)
-		if chunked {
-			dest.(io.Closer).Close()
-		}
-		if n > 0 {
-			_, _ = io.WriteString(&b, "\n")
-		}
+		c.Next()
 	}
+}
 
-	req.Body = dup
-	if err != nil {
-		return err.Error()
-	}
+// brokenPipe 是否为客户端断开连接导致的写入失败
+func brokenPipe(err interface{}) bool {
+	e, ok := err.(error)
+	return ok && (errors.Is(e, syscall.EPIPE) || errors.Is(e, syscall.ECONNRESET))
+}
 
-	return b.String()
+// peekBody 读取请求体开头（最多 maxReportBody+1 字节）并放回
+func peekBody(req *http.Request) []byte {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(req.Body, maxReportBody+1))
+	req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+	return body
 }
 
-func dupReadCloser(reader io.ReadCloser) (io.ReadCloser, io.ReadCloser) {
-	var buf bytes.Buffer
-	tee := io.TeeReader(reader, &buf)
-	return ioutil.NopCloser(tee), ioutil.NopCloser(&buf)
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
-// dumpStack returns a nicely formatted stack frame, skipping skip frames.
-func dumpStack(skip int) []byte {
-	buf := new(bytes.Buffer) // the returned data
-	// As we loop, we open files and read them. These variables record the currently
-	// loaded file.
-	var lines [][]byte
-	var lastFile string
-	for i := skip; ; i++ { // Skip the expected number of frames
-		pc, file, line, ok := runtime.Caller(i)
-		if !ok {
-			break
-		}
-		// Print this much at least.  If we can't find the source, it won't show.
-		_, _ = fmt.Fprintf(buf, "%s:%d (0x%x)\n", file, line, pc)
-		if file != lastFile {
-			data, err := ioutil.ReadFile(file)
-			if err != nil {
-				continue
-			}
-			lines = bytes.Split(data, []byte{'\n'})
-			lastFile = file
+// reportRequest 脱敏后的请求信息：敏感请求头及查询参数替换为 [REDACTED]，JSON 请求体按字段名脱敏，其他类型或过长的请求体不上报
+func reportRequest(req *http.Request, body []byte) *report.Request {
+	r := &report.Request{
+		Method:  req.Method,
+		URL:     req.URL.Path,
+		Headers: map[string]string{},
+	}
+	for k, v := range req.Header {
+		if sensitiveHeaders[http.CanonicalHeaderKey(k)] || sensitiveField.MatchString(k) {
+			r.Headers[k] = redacted
+		} else if len(v) > 0 {
+			r.Headers[k] = v[0]
 		}
+	}
 
-		_, _ = fmt.Fprintf(buf, "\t%s: %s\n", function(pc), source(lines, line))
+	r.QueryString = redactQuery(req.URL.RawQuery)
+
+	if len(body) > maxReportBody {
+		r.Data = "[body too large, omitted]"
+		return r
 	}
-	return buf.Bytes()
+	r.Data = redactBody(body)
+	return r
 }
 
-// source returns a space-trimmed slice of the n'th line.
-func source(lines [][]byte, n int) []byte {
-	n-- // in stack trace, lines are 1-indexed but our array is 0-indexed
-	if n < 0 || n >= len(lines) {
-		return dunno
+// redactQuery 查询参数按参数名脱敏
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
 	}
-	return bytes.TrimSpace(lines[n])
+	query, _ := url.ParseQuery(rawQuery)
+	for k := range query {
+		if sensitiveField.MatchString(k) {
+			query.Set(k, redacted)
+		}
+	}
+	return query.Encode()
 }
 
-// function returns, if possible, the name of the function containing the PC.
-func function(pc uintptr) []byte {
-	fn := runtime.FuncForPC(pc)
-	if fn == nil {
-		return dunno
+// redactBody JSON 请求体按字段名脱敏，其他类型的请求体不记录
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var data interface{}
+	if json.Unmarshal(body, &data) != nil {
+		return "[non-JSON body omitted]"
 	}
-	name := []byte(fn.Name())
-	if lastslash := bytes.LastIndex(name, slash); lastslash >= 0 {
-		name = name[lastslash+1:]
+	b, err := json.Marshal(redactJSON(data))
+	if err != nil {
+		return ""
 	}
-	if period := bytes.Index(name, dot); period >= 0 {
-		name = name[period+1:]
+	return string(b)
+}
+
+// redactJSON 按字段名递归脱敏
+func redactJSON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if sensitiveField.MatchString(k) {
+				val[k] = redacted
+			} else {
+				val[k] = redactJSON(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactJSON(item)
+		}
 	}
-	name = bytes.Replace(name, centerDot, dot, -1)
-	return name
+	return v
 }
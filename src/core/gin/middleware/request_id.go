@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"regexp"
+)
+
+const (
+	// RequestIdHeader 请求ID请求头/响应头，客户端或网关传入时沿用
+	RequestIdHeader = "X-Request-Id"
+	// RequestIdKey gin 上下文中请求ID的key
+	RequestIdKey = "request_id"
+)
+
+// requestIdPattern 沿用的请求ID格式，避免日志注入
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIdMiddleware 请求ID中间件，请求头中没有合法的 X-Request-Id 时生成，写入 gin 上下文及响应头
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIdHeader)
+		if !requestIdPattern.MatchString(id) {
+			buf := make([]byte, 16)
+			_, _ = rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		c.Set(RequestIdKey, id)
+		c.Header(RequestIdHeader, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/auth"
+	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/result"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SessionHeader 钱包登录会话请求头
+const SessionHeader = "session_id"
+
+// SessionMiddleware 钱包登录认证中间件：请求携带 session_id 时校验会话并将钱包地址认证主体写入请求 context，
+// 会话无效或已过期返回 Unauthorized(HTTP 401)；已通过 X-API-Key 认证时不处理
+func SessionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(SessionHeader)
+		if token == "" || auth.FromContext(c.Request.Context()) != nil {
+			c.Next()
+			return
+		}
+
+		p, err := auth.VerifySession(c.Request.Context(), token)
+		if err != nil {
+			if !errors.Is(err, auth.ErrInvalidSession) {
+				log.Logger.Error("verify session error", zap.Error(err))
+				result.Error(c, result.RedisError)
+			} else {
+				result.Error(c, result.Unauthorized)
+			}
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/core/result"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// clientContextKey gin 上下文中客户端连接 context 的key，客户端断开时取消
+	clientContextKey = "timeout_client_context"
+	// timeoutOwnerKey gin 上下文中当前生效的超时设置，嵌套设置时由最内层处理超时
+	timeoutOwnerKey = "timeout_owner"
+)
+
+// TimeoutMiddleware 请求超时中间件，按 [timeout] 配置为请求 context 设置截止时间，路由未单独配置时使用默认超时
+//
+// 超时或客户端断开时请求 context 被取消，使用 c.Request.Context() 的数据库、Redis及链上RPC调用随之中止；
+// 处理函数需将请求 context 传给下游，超时后返回 RequestTimeout(HTTP 504)
+func TimeoutMiddleware() gin.HandlerFunc {
+	conf := config.Conf.Timeout
+	defaultTimeout := time.Duration(conf.Default) * time.Millisecond
+	routes := make(map[string]time.Duration, len(conf.Routes))
+	for _, r := range conf.Routes {
+		routes[routeKey(r.Method, r.Path)] = time.Duration(r.Timeout) * time.Millisecond
+	}
+
+	return func(c *gin.Context) {
+		c.Set(clientContextKey, c.Request.Context())
+		timeout := defaultTimeout
+		for _, key := range []string{routeKey(c.Request.Method, c.FullPath()), routeKey("", c.FullPath())} {
+			if d, ok := routes[key]; ok {
+				timeout = d
+				break
+			}
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		withTimeout(c, timeout)
+	}
+}
+
+// Timeout 路由级超时，替换 TimeoutMiddleware 设置的截止时间（可比默认值更长或更短）
+//
+//	v.GET("/evm/get_block_by_num/:block_num", middleware.Timeout(20*time.Second), evmApi.GetBlockByNum)
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		withTimeout(c, timeout)
+	}
+}
+
+func withTimeout(c *gin.Context, timeout time.Duration) {
+	// 保留已写入 context 的值（认证主体、读主库标记等），去掉之前的截止时间，客户端断开时仍然取消
+	client := c.Request.Context()
+	if v, ok := c.Get(clientContextKey); ok {
+		client = v.(context.Context)
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), timeout)
+	defer cancel()
+	stop := context.AfterFunc(client, cancel)
+	defer stop()
+
+	owner := &timeout
+	c.Set(timeoutOwnerKey, owner)
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+
+	// 路由级 Timeout 已替换截止时间时由其处理
+	if v, _ := c.Get(timeoutOwnerKey); v != owner || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+	log.Logger.Warn("request timeout",
+		zap.String("method", c.Request.Method),
+		zap.String("path", c.FullPath()),
+		zap.Duration("timeout", timeout))
+	if !c.Writer.Written() {
+		result.Error(c, result.RequestTimeout)
+	}
+}
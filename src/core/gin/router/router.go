@@ -1,28 +1,33 @@
 package router
 
 import (
+	"bossfi-backend/src/core/config"
 	"bossfi-backend/src/core/gin/middleware"
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"time"
 )
 
 func InitRouter() *gin.Engine {
 	gin.ForceConsoleColor()
 	gin.SetMode(gin.ReleaseMode)
-	r := gin.New()                             // 新建一个gin引擎实例
-	r.Use(middleware.HttpLogMiddleware())      // 使用日志中间件
-	r.Use(middleware.LanguageMiddleware())     // 使用语言中间件
-	r.Use(middleware.RecoverPanicMiddleware()) // 使用恢复中间件
+	r := gin.New() // 新建一个gin引擎实例
+	// 只信任配置的反向代理，限流、审计等使用的 c.ClientIP() 不受客户端伪造的 X-Forwarded-For 影响
+	if err := r.SetTrustedProxies(config.Conf.App.TrustedProxies); err != nil {
+		panic(err)
+	}
+	r.Use(middleware.RequestIdMiddleware())      // 使用请求ID中间件
+	r.Use(middleware.CompressMiddleware())       // 使用响应压缩中间件
+	r.Use(middleware.HttpLogMiddleware())        // 使用日志中间件
+	r.Use(middleware.LanguageMiddleware())       // 使用语言中间件
+	r.Use(middleware.RecoverPanicMiddleware())   // 使用恢复中间件
+	r.Use(middleware.ReadYourWritesMiddleware()) // 使用读写分离读主库中间件
+	r.Use(middleware.AuditMiddleware())          // 使用审计日志请求信息中间件
+	r.Use(middleware.TimeoutMiddleware())        // 使用请求超时中间件
 
-	r.Use(cors.New(cors.Config{ // 使用cors中间件
-		AllowAllOrigins:  true,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "X-CSRF-Token", "Authorization", "AccessToken", "Token"},
-		ExposeHeaders:    []string{"Content-Length", "Content-Type", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "X-GW-Error-Code", "X-GW-Error-Message"},
-		AllowCredentials: true,
-		MaxAge:           1 * time.Hour,
-	}))
+	r.Use(middleware.CorsMiddleware())        // 使用cors中间件
+	r.Use(middleware.ApiKeyMiddleware())      // 使用API Key认证中间件
+	r.Use(middleware.SessionMiddleware())     // 使用钱包登录认证中间件
+	r.Use(middleware.RateLimitMiddleware())   // 使用限流中间件
+	r.Use(middleware.IdempotencyMiddleware()) // 使用幂等中间件
 
 	return r
 }
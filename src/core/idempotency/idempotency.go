@@ -0,0 +1,173 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrNotFound 幂等键没有已完成的记录
+	ErrNotFound = errors.New("idempotency record not found")
+	// ErrInProgress 相同幂等键的请求正在处理中
+	ErrInProgress = errors.New("idempotency key in progress")
+)
+
+// unlockScript 仅当处理锁仍由自己持有时删除
+var unlockScript = redis.NewScript(1, `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// refreshScript 仅当处理锁仍由自己持有时续期
+var refreshScript = redis.NewScript(1, `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Pool Redis连接池
+type Pool interface {
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
+// Record 幂等键对应的请求指纹及最终响应
+type Record struct {
+	Fingerprint string      `json:"fingerprint"` // 请求指纹（方法、路径及请求体的哈希）
+	Status      int         `json:"status"`      // HTTP状态码
+	Header      http.Header `json:"header"`      // 需要重放的响应头
+	Body        []byte      `json:"body"`        // 响应体
+}
+
+// Store 基于Redis的幂等记录存储，同一幂等键的并发请求通过处理锁互斥
+//
+// 处理锁不使用 lock 包，避免每个幂等键遗留永久的 fencing 计数器
+type Store struct {
+	pool      Pool
+	namespace string
+}
+
+// New 创建幂等记录存储，namespace 为key前缀，一般为应用名
+func New(pool Pool, namespace string) *Store {
+	return &Store{pool: pool, namespace: namespace}
+}
+
+func (s *Store) key(key string) string {
+	k := "idem:" + key
+	if s.namespace != "" {
+		k = s.namespace + ":" + k
+	}
+	return k
+}
+
+// Get 查询已完成的记录，不存在返回 ErrNotFound
+func (s *Store) Get(ctx context.Context, key string) (*Record, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	data, err := redis.Bytes(redis.DoContext(conn, ctx, "GET", s.key(key)))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Save 保存最终响应，ttl 内相同幂等键的请求直接重放
+func (s *Store) Save(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = redis.DoContext(conn, ctx, "SET", s.key(key), data, "PX", ttl.Milliseconds())
+	return err
+}
+
+// Lock 获取幂等键的处理锁，返回释放函数；已有相同幂等键的请求在处理中时返回 ErrInProgress
+//
+// 持有期间每 ttl/3 续期一次，处理时间不受 ttl 限制；进程异常退出时锁最长 ttl 后过期
+func (s *Store) Lock(ctx context.Context, key string, ttl time.Duration) (func(ctx context.Context) error, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(buf)
+
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	lockKey := s.key(key) + ":lock"
+	_, err = redis.String(redis.DoContext(conn, ctx, "SET", lockKey, token, "NX", "PX", ttl.Milliseconds()))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, ErrInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keepCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	go s.keepAlive(keepCtx, lockKey, token, ttl)
+	return func(ctx context.Context) error {
+		stop()
+		conn, err := s.pool.GetContext(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		_, err = unlockScript.DoContext(ctx, conn, lockKey, token)
+		return err
+	}, nil
+}
+
+// keepAlive 定期续期处理锁，直到 ctx 取消或锁已不再由自己持有
+func (s *Store) keepAlive(ctx context.Context, lockKey, token string, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		ok, err := s.refresh(ctx, lockKey, token, ttl)
+		if err == nil && !ok {
+			return
+		}
+	}
+}
+
+func (s *Store) refresh(ctx context.Context, lockKey, token string, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, ttl/3)
+	defer cancel()
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	return redis.Bool(refreshScript.DoContext(ctx, conn, lockKey, token, ttl.Milliseconds()))
+}
@@ -0,0 +1,52 @@
+package lock
+
+import (
+	"bossfi-backend/src/core/log"
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+// Election 基于分布式锁的主节点选举，多副本中只有一个实例执行单例任务（索引器、定时任务等），
+// 主节点宕机或失去锁后其他副本在 ttl 内自动接管
+type Election struct {
+	locker *Locker
+	name   string
+	ttl    time.Duration
+	leader atomic.Bool
+}
+
+// NewElection 创建选举，name 为任务名称，ttl 为主节点失联后的最长接管时间
+func (l *Locker) NewElection(name string, ttl time.Duration) *Election {
+	return &Election{locker: l, name: "election:" + name, ttl: ttl}
+}
+
+// IsLeader 当前实例是否为主节点
+func (e *Election) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run 持续参与选举直到 ctx 结束：成为主节点后执行 fn，失去主节点身份时取消 fn 的 ctx；
+// fn 返回后主动让出主节点并重新参与选举
+func (e *Election) Run(ctx context.Context, fn func(ctx context.Context, fence int64) error) {
+	retry := e.ttl / 3
+	for {
+		err := e.locker.Run(ctx, e.name, e.ttl, func(ctx context.Context, fence int64) error {
+			e.leader.Store(true)
+			defer e.leader.Store(false)
+			log.Logger.Info("elected as leader", zap.String("name", e.name), zap.Int64("fence", fence))
+			return fn(ctx, fence)
+		})
+		if err != nil && !errors.Is(err, ErrNotAcquired) && !errors.Is(err, context.Canceled) {
+			log.Logger.Warn("leader election error", zap.String("name", e.name), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
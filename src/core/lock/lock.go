@@ -0,0 +1,234 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNotAcquired 锁已被其他持有者占用
+	ErrNotAcquired = errors.New("lock not acquired")
+	// ErrLockLost 锁已过期或被其他持有者获取
+	ErrLockLost = errors.New("lock lost")
+)
+
+// Default 默认锁实例，InitLock 后可用
+var Default *Locker
+
+// Pool Redis连接池
+type Pool interface {
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
+// acquireScript 获取锁并递增 fencing token
+// KEYS[1] 锁 KEYS[2] fencing 计数器 ARGV[1] 持有者token ARGV[2] 过期时间(毫秒)
+var acquireScript = redis.NewScript(2, `
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+// releaseScript 仅当锁仍由自己持有时删除
+var releaseScript = redis.NewScript(1, `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// renewScript 仅当锁仍由自己持有时续期
+var renewScript = redis.NewScript(1, `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Locker 基于Redis的分布式锁
+type Locker struct {
+	pool      Pool
+	namespace string
+}
+
+// New 创建分布式锁，namespace 为key前缀，一般为应用名
+func New(pool Pool, namespace string) *Locker {
+	return &Locker{pool: pool, namespace: namespace}
+}
+
+// Lock 已获取的锁
+type Lock struct {
+	locker *Locker
+	name   string
+	token  string
+	fence  int64
+	ttl    atomic.Int64 // 当前过期时间，Refresh 与 KeepAlive 可能在不同 goroutine 中访问
+}
+
+// key 锁key，使用 {hash tag} 保证集群模式下锁与 fencing 计数器在同一槽位
+func (l *Locker) key(name string) string {
+	key := "lock:{" + name + "}"
+	if l.namespace != "" {
+		key = l.namespace + ":" + key
+	}
+	return key
+}
+
+// TryLock 尝试获取锁，已被占用时返回 ErrNotAcquired
+func (l *Locker) TryLock(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := l.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	key := l.key(name)
+	fence, err := redis.Int64(acquireScript.Do(conn, key, key+":fence", token, ttl.Milliseconds()))
+	if err != nil {
+		return nil, err
+	}
+	if fence == 0 {
+		return nil, ErrNotAcquired
+	}
+	lk := &Lock{locker: l, name: name, token: token, fence: fence}
+	lk.ttl.Store(int64(ttl))
+	return lk, nil
+}
+
+// Lock 获取锁，锁被占用时每隔 retry 重试直到获取成功或 ctx 结束
+func (l *Locker) Lock(ctx context.Context, name string, ttl, retry time.Duration) (*Lock, error) {
+	for {
+		lk, err := l.TryLock(ctx, name, ttl)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lk, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
+
+// Run 持有锁执行 fn，执行期间自动续期；续期失败（锁丢失）时取消 fn 的 ctx，fn 返回后释放锁
+func (l *Locker) Run(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context, fence int64) error) error {
+	lk, err := l.TryLock(ctx, name, ttl)
+	if err != nil {
+		return err
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	lost := lk.KeepAlive(runCtx)
+	go func() {
+		select {
+		case <-lost:
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	err = fn(runCtx, lk.Fence())
+	cancel()
+	if unlockErr := lk.Unlock(context.Background()); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+// Name 锁名称
+func (lk *Lock) Name() string {
+	return lk.name
+}
+
+// Fence fencing token，每次获取锁单调递增；写外部资源时携带该值，资源方拒绝小于已见最大值的请求，
+// 防止锁过期后旧持有者的延迟写入覆盖新持有者的数据
+func (lk *Lock) Fence() int64 {
+	return lk.fence
+}
+
+// Unlock 释放锁，锁已不属于自己时返回 ErrLockLost
+func (lk *Lock) Unlock(ctx context.Context) error {
+	return lk.eval(ctx, releaseScript, lk.token)
+}
+
+// Refresh 续期锁，锁已不属于自己时返回 ErrLockLost
+func (lk *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	if err := lk.eval(ctx, renewScript, lk.token, ttl.Milliseconds()); err != nil {
+		return err
+	}
+	lk.ttl.Store(int64(ttl))
+	return nil
+}
+
+// KeepAlive 每隔 ttl/3 自动续期直到 ctx 结束，锁丢失时关闭返回的 channel
+//
+// 网络错误会重试，距上次续期成功（按发起续期的时间计）达到 2/3 ttl 仍未成功时视为锁丢失，
+// 使持有者在锁实际过期前停止工作
+func (lk *Lock) KeepAlive(ctx context.Context) <-chan struct{} {
+	lost := make(chan struct{})
+	go func() {
+		ttl := time.Duration(lk.ttl.Load())
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		renewed := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			ttl = time.Duration(lk.ttl.Load())
+			start := time.Now()
+			// 单次续期不超过续期间隔，避免阻塞到锁过期
+			refreshCtx, cancel := context.WithTimeout(ctx, ttl/3)
+			err := lk.Refresh(refreshCtx, ttl)
+			cancel()
+			if err == nil {
+				renewed = start
+				continue
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, ErrLockLost) || time.Since(renewed) >= ttl*2/3 {
+				close(lost)
+				return
+			}
+		}
+	}()
+	return lost
+}
+
+func (lk *Lock) eval(ctx context.Context, script *redis.Script, args ...interface{}) error {
+	conn, err := lk.locker.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	n, err := redis.Int(script.DoContext(ctx, conn, append([]interface{}{lk.locker.key(lk.name)}, args...)...))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
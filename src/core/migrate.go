@@ -0,0 +1,104 @@
+package core
+
+import (
+	"bossfi-backend/src/core/config"
+	"bossfi-backend/src/core/db"
+	"bossfi-backend/src/core/db/migrate"
+	"bossfi-backend/src/core/log"
+	"bossfi-backend/src/migrations"
+	"context"
+	"flag"
+	"fmt"
+	"go.uber.org/zap"
+)
+
+// initMigrate 启动时自动执行数据库迁移
+func initMigrate() {
+	if !autoMigrate() {
+		return
+	}
+	log.Logger.Info("auto migrate")
+	m, err := newMigrator()
+	if err == nil {
+		err = m.Up(context.Background(), logMigrate)
+	}
+	if err != nil {
+		log.Logger.Error("auto migrate error", zap.Error(err))
+		panic(err)
+	}
+}
+
+// runMigrate 数据库迁移 用法: migrate up | down [-n 1] | status | seed [-env dev]
+func runMigrate(configFile string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up | down [-n 1] | status | seed [-env dev]")
+	}
+
+	initConfig(configFile)
+	initLog()
+	initDataSources()
+
+	m, err := newMigrator()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	switch args[0] {
+	case "up":
+		return m.Up(ctx, logMigrate)
+	case "down":
+		steps := fs.Int("n", 1, "number of migrations to roll back")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return m.Down(ctx, *steps, logMigrate)
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range list {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d  %-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+		return nil
+	case "seed":
+		env := fs.String("env", config.Conf.App.Env, "seed environment")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *env == "" {
+			return fmt.Errorf("seed environment not specified, use -env or [app] env")
+		}
+		return m.Seed(ctx, *env, logMigrate)
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
+
+func newMigrator() (*migrate.Migrator, error) {
+	// 迁移脚本及 advisory lock 依赖 PostgreSQL
+	pgsql, ok := db.Get(db.SourcePgsql)
+	if !ok {
+		return nil, fmt.Errorf("migrations require the pgsql data source")
+	}
+	sqlDB, err := pgsql.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, migrations.Schema(), migrations.Seed())
+}
+
+// autoMigrate 是否启动时自动迁移，兼容旧配置 [pgsql] auto_migrate
+func autoMigrate() bool {
+	return config.Conf.DataSource.AutoMigrate || config.Conf.Pgsql.AutoMigrate
+}
+
+func logMigrate(msg string) {
+	log.Logger.Info(msg)
+}
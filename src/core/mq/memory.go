@@ -0,0 +1,151 @@
+package mq
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryBus 进程内消息总线，语义与 RedisBus 一致（消费组、重试、死信），用于测试及单机开发
+type MemoryBus struct {
+	opts   Options
+	seq    atomic.Int64
+	closed atomic.Bool
+
+	mu     sync.Mutex
+	groups map[string]map[string]*memoryQueue // topic -> group -> 队列
+	dead   map[string][]*Message
+}
+
+// NewMemory 创建进程内消息总线
+func NewMemory(opts Options) *MemoryBus {
+	return &MemoryBus{
+		opts:   opts.withDefaults(),
+		groups: map[string]map[string]*memoryQueue{},
+		dead:   map[string][]*Message{},
+	}
+}
+
+// memoryQueue 消费组队列，同组多个消费者竞争消费
+type memoryQueue struct {
+	mu     sync.Mutex
+	msgs   []*Message
+	notify chan struct{}
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{notify: make(chan struct{}, 1)}
+}
+
+func (q *memoryQueue) push(msg *Message) {
+	q.mu.Lock()
+	q.msgs = append(q.msgs, msg)
+	q.mu.Unlock()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *memoryQueue) pop() (*Message, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.msgs) == 0 {
+		return nil, false
+	}
+	msg := q.msgs[0]
+	q.msgs = q.msgs[1:]
+	// 仍有消息时唤醒其他消费者
+	if len(q.msgs) > 0 {
+		select {
+		case q.notify <- struct{}{}:
+		default:
+		}
+	}
+	return msg, true
+}
+
+// Publish 发布消息，投递到 topic 已存在的全部消费组
+func (b *MemoryBus) Publish(_ context.Context, topic string, payload []byte) (string, error) {
+	if b.closed.Load() {
+		return "", ErrClosed
+	}
+	id := strconv.FormatInt(time.Now().UnixMilli(), 10) + "-" + strconv.FormatInt(b.seq.Add(1), 10)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, q := range b.groups[topic] {
+		q.push(&Message{ID: id, Topic: topic, Payload: payload, Attempt: 1})
+	}
+	return id, nil
+}
+
+// Subscribe 消费消息，阻塞直到 ctx 结束或总线关闭
+func (b *MemoryBus) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	q := b.queue(topic, group)
+	for ctx.Err() == nil && !b.closed.Load() {
+		msg, ok := q.pop()
+		if !ok {
+			select {
+			case <-ctx.Done():
+			case <-q.notify:
+			case <-time.After(readBlock):
+			}
+			continue
+		}
+
+		err := handle(ctx, handler, msg)
+		if err == nil {
+			continue
+		}
+		if msg.Attempt > b.opts.MaxRetries {
+			b.mu.Lock()
+			b.dead[topic] = append(b.dead[topic], &Message{
+				ID: msg.ID, Topic: topic, Payload: msg.Payload, Attempt: msg.Attempt, Group: group, Error: err.Error(),
+			})
+			b.mu.Unlock()
+			continue
+		}
+		retry := &Message{ID: msg.ID, Topic: topic, Payload: msg.Payload, Attempt: msg.Attempt + 1}
+		time.AfterFunc(b.opts.RetryDelay, func() {
+			q.push(retry)
+		})
+	}
+	return nil
+}
+
+func (b *MemoryBus) queue(topic, group string) *memoryQueue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	groups, ok := b.groups[topic]
+	if !ok {
+		groups = map[string]*memoryQueue{}
+		b.groups[topic] = groups
+	}
+	q, ok := groups[group]
+	if !ok {
+		q = newMemoryQueue()
+		groups[group] = q
+	}
+	return q
+}
+
+// DeadLetters 查询最近的死信消息，按时间倒序
+func (b *MemoryBus) DeadLetters(_ context.Context, topic string, count int) ([]*Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	dead := b.dead[topic]
+	msgs := make([]*Message, 0, count)
+	for i := len(dead) - 1; i >= 0 && len(msgs) < count; i-- {
+		msgs = append(msgs, dead[i])
+	}
+	return msgs, nil
+}
+
+// Close 关闭消息总线
+func (b *MemoryBus) Close() error {
+	b.closed.Store(true)
+	return nil
+}
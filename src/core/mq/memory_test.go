@@ -0,0 +1,202 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// subscribe 在后台消费，测试结束时停止；先创建消费组，避免订阅前发布的消息丢失
+func subscribe(t *testing.T, bus *MemoryBus, topic, group string, handler Handler) {
+	t.Helper()
+	bus.queue(topic, group)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = bus.Subscribe(ctx, topic, group, handler)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMemoryBusGroups(t *testing.T) {
+	bus := NewMemory(Options{})
+	const total = 20
+
+	var mu sync.Mutex
+	seen := map[string]map[string]int{} // group -> payload -> 处理次数
+	count := func(group string) Handler {
+		return func(_ context.Context, msg *Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[group] == nil {
+				seen[group] = map[string]int{}
+			}
+			seen[group][string(msg.Payload)]++
+			return nil
+		}
+	}
+	// a 组两个消费者竞争消费，b 组一个消费者
+	subscribe(t, bus, "topic", "a", count("a"))
+	subscribe(t, bus, "topic", "a", count("a"))
+	subscribe(t, bus, "topic", "b", count("b"))
+
+	for i := 0; i < total; i++ {
+		if _, err := bus.Publish(context.Background(), "topic", []byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(seen["a"]) == total && len(seen["b"]) == total
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	for group, payloads := range seen {
+		for payload, n := range payloads {
+			if n != 1 {
+				t.Errorf("group %s handled %s %d times, want 1", group, payload, n)
+			}
+		}
+	}
+}
+
+func TestMemoryBusRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int  // 前几次投递处理失败
+		panics    bool // 失败方式为 panic
+		attempts  int  // 期望的投递次数
+		deadError string
+	}{
+		{"success", 0, false, 1, ""},
+		{"retry then success", 2, false, 3, ""},
+		{"dead letter", 10, false, 3, "boom"},
+		{"panic dead letter", 10, true, 3, "handler panic: boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewMemory(Options{MaxRetries: 2, RetryDelay: time.Millisecond})
+			var mu sync.Mutex
+			var attempts []int
+			subscribe(t, bus, "topic", "g", func(_ context.Context, msg *Message) error {
+				mu.Lock()
+				attempts = append(attempts, msg.Attempt)
+				mu.Unlock()
+				if msg.Attempt > tt.failures {
+					return nil
+				}
+				if tt.panics {
+					panic("boom")
+				}
+				return errors.New("boom")
+			})
+			id, err := bus.Publish(context.Background(), "topic", []byte("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitFor(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(attempts) == tt.attempts
+			})
+			if tt.deadError != "" {
+				waitFor(t, func() bool {
+					dead, _ := bus.DeadLetters(context.Background(), "topic", 10)
+					return len(dead) == 1
+				})
+			}
+			// 等待可能的多余重试
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for i, a := range attempts {
+				if a != i+1 {
+					t.Errorf("attempts = %v, want 1..%d", attempts, tt.attempts)
+					break
+				}
+			}
+			if len(attempts) != tt.attempts {
+				t.Errorf("delivered %d times, want %d", len(attempts), tt.attempts)
+			}
+			dead, _ := bus.DeadLetters(context.Background(), "topic", 10)
+			if tt.deadError == "" {
+				if len(dead) != 0 {
+					t.Errorf("dead letters = %d, want 0", len(dead))
+				}
+				return
+			}
+			if len(dead) != 1 {
+				t.Fatalf("dead letters = %d, want 1", len(dead))
+			}
+			d := dead[0]
+			if d.ID != id || d.Group != "g" || d.Attempt != tt.attempts || !strings.Contains(d.Error, tt.deadError) {
+				t.Errorf("dead letter = %+v", d)
+			}
+		})
+	}
+}
+
+type testEvent struct {
+	Name string `json:"name"`
+}
+
+func (testEvent) Topic() string {
+	return "test_event"
+}
+
+func TestMemoryBusEvent(t *testing.T) {
+	bus := NewMemory(Options{})
+	got := make(chan testEvent, 1)
+	bus.queue(testEvent{}.Topic(), "g")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = Subscribe(ctx, bus, "g", func(_ context.Context, e testEvent) error {
+			got <- e
+			return nil
+		})
+	}()
+
+	if _, err := Publish(context.Background(), bus, testEvent{Name: "created"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-got:
+		if e.Name != "created" {
+			t.Errorf("event = %+v", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event not delivered")
+	}
+
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Publish(context.Background(), "topic", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish after Close = %v, want ErrClosed", err)
+	}
+}
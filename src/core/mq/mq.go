@@ -0,0 +1,117 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 消息队列驱动
+const (
+	DriverRedis  = "redis"
+	DriverMemory = "memory"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = 30 * time.Second
+	defaultMaxLen     = 100000
+	defaultBatch      = 10
+)
+
+// ErrClosed 消息总线已关闭
+var ErrClosed = errors.New("mq closed")
+
+// Default 默认消息总线，InitMQ 后可用
+var Default Bus
+
+// Message 消息
+type Message struct {
+	ID      string `json:"id"`
+	Topic   string `json:"topic"`
+	Payload []byte `json:"payload"`
+	Attempt int    `json:"attempt"` // 第几次投递，从 1 开始
+
+	// 以下字段仅死信消息有值
+	Group string `json:"group,omitempty"` // 处理失败的消费组
+	Error string `json:"error,omitempty"` // 最后一次处理失败的原因
+}
+
+// Handler 消息处理函数，返回 error 时消息在 RetryDelay 后重新投递，超过 MaxRetries 后进入死信队列
+type Handler func(ctx context.Context, msg *Message) error
+
+// Bus 消息总线
+//
+// 同一 topic 的每个消费组都会收到全部消息，同一消费组内的多个消费者（多副本）竞争消费，每条消息只被其中一个处理
+type Bus interface {
+	// Publish 发布消息，返回消息ID
+	Publish(ctx context.Context, topic string, payload []byte) (string, error)
+	// Subscribe 以消费组 group 消费 topic，阻塞直到 ctx 结束；消费组不存在时自动创建，从创建后发布的消息开始消费
+	Subscribe(ctx context.Context, topic, group string, handler Handler) error
+	// DeadLetters 查询 topic 最近的死信消息
+	DeadLetters(ctx context.Context, topic string, count int) ([]*Message, error)
+	// Close 关闭消息总线
+	Close() error
+}
+
+// Options 消费选项
+type Options struct {
+	MaxRetries int           // 处理失败最大重试次数，超过后进入死信队列，默认 3
+	RetryDelay time.Duration // 处理失败后重新投递的等待时间，默认 30 秒
+	MaxLen     int64         // 每个 topic 保留的最大消息数（近似），默认 100000
+	Batch      int           // 每次拉取消息数，默认 10
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = defaultMaxRetries
+	}
+	if o.RetryDelay <= 0 {
+		o.RetryDelay = defaultRetryDelay
+	}
+	if o.MaxLen <= 0 {
+		o.MaxLen = defaultMaxLen
+	}
+	if o.Batch <= 0 {
+		o.Batch = defaultBatch
+	}
+	return o
+}
+
+// Event 事件，Topic 为事件发布的 topic
+type Event interface {
+	Topic() string
+}
+
+// Publish 以 JSON 编码发布事件
+func Publish[T Event](ctx context.Context, bus Bus, event T) (string, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	return bus.Publish(ctx, event.Topic(), data)
+}
+
+// Subscribe 以消费组 group 消费事件 T，阻塞直到 ctx 结束
+func Subscribe[T Event](ctx context.Context, bus Bus, group string, handler func(ctx context.Context, event T) error) error {
+	var zero T
+	return bus.Subscribe(ctx, zero.Topic(), group, func(ctx context.Context, msg *Message) error {
+		var event T
+		if err := json.Unmarshal(msg.Payload, &event); err != nil {
+			return fmt.Errorf("decode %s event: %w", msg.Topic, err)
+		}
+		return handler(ctx, event)
+	})
+}
+
+// handle 执行消息处理函数，处理函数 panic 时视为处理失败
+func handle(ctx context.Context, handler Handler, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
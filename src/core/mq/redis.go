@@ -0,0 +1,323 @@
+package mq
+
+import (
+	"bossfi-backend/src/core/log"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// readBlock XREADGROUP 阻塞等待时间
+	readBlock = 2 * time.Second
+	// readTimeout 阻塞读取的连接读超时，需大于 readBlock
+	readTimeout = readBlock + 3*time.Second
+	// errorBackoff 读取失败后的等待时间
+	errorBackoff = time.Second
+)
+
+// Pool Redis连接池
+type Pool interface {
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
+// RedisBus 基于 Redis Streams 的消息总线：每个 topic 一个 stream，消费组对应 XGROUP，
+// 处理成功后 XACK；失败的消息留在 pending 列表中，空闲超过 RetryDelay 后被重新认领投递，
+// 投递次数超过 MaxRetries+1 后写入死信 stream {topic}:dlq
+type RedisBus struct {
+	pool      Pool
+	namespace string
+	opts      Options
+	consumer  string
+	closed    atomic.Bool
+}
+
+// NewRedis 创建 Redis Streams 消息总线，namespace 为key前缀，一般为应用名
+func NewRedis(pool Pool, namespace string, opts Options) *RedisBus {
+	host, _ := os.Hostname()
+	return &RedisBus{
+		pool:      pool,
+		namespace: namespace,
+		opts:      opts.withDefaults(),
+		consumer:  fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}
+}
+
+func (b *RedisBus) stream(topic string) string {
+	key := "mq:" + topic
+	if b.namespace != "" {
+		key = b.namespace + ":" + key
+	}
+	return key
+}
+
+func (b *RedisBus) deadStream(topic string) string {
+	return b.stream(topic) + ":dlq"
+}
+
+// Publish 发布消息
+func (b *RedisBus) Publish(ctx context.Context, topic string, payload []byte) (string, error) {
+	if b.closed.Load() {
+		return "", ErrClosed
+	}
+	return b.xadd(ctx, b.stream(topic), "payload", payload)
+}
+
+func (b *RedisBus) xadd(ctx context.Context, stream string, fields ...interface{}) (string, error) {
+	conn, err := b.pool.GetContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	args := append([]interface{}{stream, "MAXLEN", "~", b.opts.MaxLen, "*"}, fields...)
+	return redis.String(redis.DoContext(conn, ctx, "XADD", args...))
+}
+
+// Subscribe 消费消息，阻塞直到 ctx 结束或总线关闭
+func (b *RedisBus) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	stream := b.stream(topic)
+	if err := b.createGroup(ctx, stream, group); err != nil {
+		return err
+	}
+
+	var lastClaim time.Time
+	for ctx.Err() == nil && !b.closed.Load() {
+		// 定期认领空闲超时（处理失败或消费者宕机）的消息重新投递
+		if time.Since(lastClaim) >= b.opts.RetryDelay/2 {
+			if err := b.claim(ctx, topic, group, handler); err != nil {
+				log.Logger.Warn("mq claim pending error", zap.String("topic", topic), zap.String("group", group), zap.Error(err))
+			}
+			lastClaim = time.Now()
+		}
+
+		msgs, err := b.read(ctx, topic, group)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			log.Logger.Warn("mq read error", zap.String("topic", topic), zap.String("group", group), zap.Error(err))
+			select {
+			case <-ctx.Done():
+			case <-time.After(errorBackoff):
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			b.process(ctx, group, msg, handler)
+		}
+	}
+	return nil
+}
+
+// createGroup 创建消费组，已存在时忽略
+func (b *RedisBus) createGroup(ctx context.Context, stream, group string) error {
+	conn, err := b.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = redis.DoContext(conn, ctx, "XGROUP", "CREATE", stream, group, "$", "MKSTREAM")
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// read 拉取新消息
+func (b *RedisBus) read(ctx context.Context, topic, group string) ([]*Message, error) {
+	conn, err := b.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// 阻塞时间不超过认领间隔，保证失败消息按时重试
+	block := min(readBlock, b.opts.RetryDelay/2)
+	reply, err := redis.Values(redis.DoWithTimeout(conn, readTimeout, "XREADGROUP", "GROUP", group, b.consumer,
+		"COUNT", b.opts.Batch, "BLOCK", max(block.Milliseconds(), 1), "STREAMS", b.stream(topic), ">"))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// [[stream, [[id, [field, value, ...]], ...]]]
+	var msgs []*Message
+	for _, s := range reply {
+		fields, err := redis.Values(s, nil)
+		if err != nil || len(fields) != 2 {
+			return nil, errors.New("invalid XREADGROUP reply")
+		}
+		entries, err := parseEntries(topic, fields[1])
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range entries {
+			msg.Attempt = 1
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+// claim 认领空闲超过 RetryDelay 的 pending 消息，投递次数超限的写入死信队列
+func (b *RedisBus) claim(ctx context.Context, topic, group string, handler Handler) error {
+	conn, err := b.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream := b.stream(topic)
+	minIdle := b.opts.RetryDelay.Milliseconds()
+	pending, err := redis.Values(redis.DoContext(conn, ctx, "XPENDING", stream, group, "IDLE", minIdle, "-", "+", b.opts.Batch))
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pending {
+		// [id, consumer, idle, deliveries]
+		fields, err := redis.Values(p, nil)
+		if err != nil || len(fields) != 4 {
+			return errors.New("invalid XPENDING reply")
+		}
+		id, _ := redis.String(fields[0], nil)
+		deliveries, _ := redis.Int(fields[3], nil)
+
+		reply, err := redis.DoContext(conn, ctx, "XCLAIM", stream, group, b.consumer, minIdle, id)
+		if err != nil {
+			return err
+		}
+		entries, err := parseEntries(topic, reply)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			// 未认领到：消息已被 MAXLEN 裁剪，或在 XPENDING 之后被其他消费者认领（空闲时间不再满足）；
+			// 只确认已确定被删除的消息，其他消费者正在处理的消息不能确认
+			exists, err := redis.Values(redis.DoContext(conn, ctx, "XRANGE", stream, id, id, "COUNT", 1))
+			if err != nil {
+				return err
+			}
+			if len(exists) == 0 {
+				_, _ = redis.DoContext(conn, ctx, "XACK", stream, group, id)
+			}
+			continue
+		}
+
+		msg := entries[0]
+		msg.Attempt = deliveries + 1
+		if msg.Attempt > b.opts.MaxRetries+1 {
+			b.deadLetter(ctx, group, msg, "max deliveries exceeded")
+			continue
+		}
+		b.process(ctx, group, msg, handler)
+	}
+	return nil
+}
+
+// process 处理消息：成功确认，失败且超过重试次数写入死信队列，否则等待重新投递
+func (b *RedisBus) process(ctx context.Context, group string, msg *Message, handler Handler) {
+	err := handle(ctx, handler, msg)
+	if err == nil {
+		if err := b.ack(ctx, msg.Topic, group, msg.ID); err != nil {
+			log.Logger.Warn("mq ack error", zap.String("topic", msg.Topic), zap.String("id", msg.ID), zap.Error(err))
+		}
+		return
+	}
+
+	if msg.Attempt > b.opts.MaxRetries {
+		b.deadLetter(ctx, group, msg, err.Error())
+		return
+	}
+	log.Logger.Warn("mq handle message error, will retry",
+		zap.String("topic", msg.Topic), zap.String("group", group), zap.String("id", msg.ID),
+		zap.Int("attempt", msg.Attempt), zap.Error(err))
+}
+
+func (b *RedisBus) ack(ctx context.Context, topic, group, id string) error {
+	conn, err := b.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = redis.DoContext(conn, ctx, "XACK", b.stream(topic), group, id)
+	return err
+}
+
+// deadLetter 写入死信队列并确认原消息
+func (b *RedisBus) deadLetter(ctx context.Context, group string, msg *Message, reason string) {
+	log.Logger.Error("mq message moved to dead letter queue",
+		zap.String("topic", msg.Topic), zap.String("group", group), zap.String("id", msg.ID),
+		zap.Int("attempt", msg.Attempt), zap.String("error", reason))
+
+	_, err := b.xadd(ctx, b.deadStream(msg.Topic),
+		"payload", msg.Payload, "source_id", msg.ID, "group", group, "attempt", msg.Attempt, "error", reason)
+	if err != nil {
+		// 写入失败时不确认，等待下次认领
+		log.Logger.Error("mq write dead letter error", zap.String("topic", msg.Topic), zap.Error(err))
+		return
+	}
+	if err := b.ack(ctx, msg.Topic, group, msg.ID); err != nil {
+		log.Logger.Warn("mq ack error", zap.String("topic", msg.Topic), zap.String("id", msg.ID), zap.Error(err))
+	}
+}
+
+// DeadLetters 查询最近的死信消息，按时间倒序
+func (b *RedisBus) DeadLetters(ctx context.Context, topic string, count int) ([]*Message, error) {
+	conn, err := b.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	reply, err := redis.DoContext(conn, ctx, "XREVRANGE", b.deadStream(topic), "+", "-", "COUNT", count)
+	if err != nil {
+		return nil, err
+	}
+	return parseEntries(topic, reply)
+}
+
+// Close 关闭消息总线，正在消费的 Subscribe 在本轮拉取结束后返回
+func (b *RedisBus) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+// parseEntries 解析 stream 条目 [[id, [field, value, ...]], ...]，已删除的条目（值为 nil）跳过
+func parseEntries(topic string, reply interface{}) ([]*Message, error) {
+	entries, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]*Message, 0, len(entries))
+	for _, e := range entries {
+		entry, err := redis.Values(e, nil)
+		if err != nil || len(entry) != 2 {
+			return nil, errors.New("invalid stream entry")
+		}
+		if entry[1] == nil {
+			continue
+		}
+		id, _ := redis.String(entry[0], nil)
+		values, err := redis.StringMap(entry[1], nil)
+		if err != nil {
+			return nil, err
+		}
+		msg := &Message{ID: id, Topic: topic, Payload: []byte(values["payload"])}
+		if values["source_id"] != "" {
+			msg.ID = values["source_id"]
+			msg.Group = values["group"]
+			msg.Error = values["error"]
+			msg.Attempt, _ = strconv.Atoi(values["attempt"])
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
@@ -0,0 +1,95 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// MergePatchContentType JSON Merge Patch 请求类型
+const MergePatchContentType = "application/merge-patch+json"
+
+var (
+	// ErrInvalid patch 不是合法的 JSON 对象，或合并结果无法解码
+	ErrInvalid = errors.New("invalid merge patch")
+	// ErrNotWritable patch 包含不允许修改的字段
+	ErrNotWritable = errors.New("field not writable")
+)
+
+// Merge 按 RFC 7386 将 patch 合并到 doc：对象逐字段合并，值为 null 时删除字段，其他值（包括数组）整体替换
+func Merge(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := decode(doc, &target); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err := decode(patch, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return json.Marshal(merge(target, p))
+}
+
+// Apply 将 patch 合并到 v（v 为可修改字段组成的结构体指针）：patch 必须为 JSON 对象，顶层字段只能是 v 序列化后的字段，
+// 否则返回 ErrNotWritable；值为 null 的字段重置为零值
+func Apply(v interface{}, patch []byte) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("patch target must be a non-nil pointer")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil || fields == nil {
+		return ErrInvalid
+	}
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var writable map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &writable); err != nil {
+		return err
+	}
+	for name := range fields {
+		if _, ok := writable[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrNotWritable, name)
+		}
+	}
+
+	merged, err := Merge(doc, patch)
+	if err != nil {
+		return err
+	}
+	// 先重置为零值，合并后被删除的字段不保留原值
+	rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+	if err := json.Unmarshal(merged, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return nil
+}
+
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = merge(t[k], v)
+	}
+	return t
+}
+
+// decode 解码 JSON，数字保持原始精度
+func decode(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
@@ -0,0 +1,114 @@
+package patch
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// RFC 7386 附录 A 的测试用例
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		// 数字保持原始精度
+		{`{"n":1}`, `{"n":12345678901234567890}`, `{"n":12345678901234567890}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.doc+" + "+tt.patch, func(t *testing.T) {
+			got, err := Merge([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(t, got, []byte(tt.want)) {
+				t.Errorf("Merge = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeInvalid(t *testing.T) {
+	for _, patch := range []string{``, `{`, `{"a":}`} {
+		if _, err := Merge([]byte(`{}`), []byte(patch)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Merge(%q) = %v, want ErrInvalid", patch, err)
+		}
+	}
+}
+
+type applyTarget struct {
+	Name  string            `json:"name"`
+	Count int               `json:"count"`
+	Tags  []string          `json:"tags"`
+	Attrs map[string]string `json:"attrs"`
+}
+
+func TestApply(t *testing.T) {
+	base := applyTarget{Name: "a", Count: 1, Tags: []string{"x"}, Attrs: map[string]string{"k": "v", "d": "e"}}
+	tests := []struct {
+		name    string
+		patch   string
+		want    applyTarget
+		wantErr error
+	}{
+		{"replace field", `{"name":"b"}`,
+			applyTarget{Name: "b", Count: 1, Tags: []string{"x"}, Attrs: map[string]string{"k": "v", "d": "e"}}, nil},
+		{"null resets to zero value", `{"count":null,"tags":null}`,
+			applyTarget{Name: "a", Attrs: map[string]string{"k": "v", "d": "e"}}, nil},
+		{"nested merge", `{"attrs":{"k":"w","d":null}}`,
+			applyTarget{Name: "a", Count: 1, Tags: []string{"x"}, Attrs: map[string]string{"k": "w"}}, nil},
+		{"array replaced", `{"tags":["y","z"]}`,
+			applyTarget{Name: "a", Count: 1, Tags: []string{"y", "z"}, Attrs: map[string]string{"k": "v", "d": "e"}}, nil},
+		{"unknown field", `{"id":1}`, base, ErrNotWritable},
+		{"not an object", `["name"]`, base, ErrInvalid},
+		{"null patch", `null`, base, ErrInvalid},
+		{"wrong type", `{"count":"many"}`, applyTarget{}, ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := base
+			v.Tags = append([]string(nil), base.Tags...)
+			v.Attrs = map[string]string{"k": "v", "d": "e"}
+
+			err := Apply(&v, []byte(tt.patch))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Apply = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(v, tt.want) {
+				t.Errorf("Apply = %+v, want %+v", v, tt.want)
+			}
+		})
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb interface{}
+	if err := decode(a, &va); err != nil {
+		t.Fatal(err)
+	}
+	if err := decode(b, &vb); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
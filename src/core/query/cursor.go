@@ -0,0 +1,349 @@
+package query
+
+import (
+	"bossfi-backend/src/core/config"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 总数统计模式
+const (
+	CountExact  = "exact"  // 精确统计 count(*)
+	CountApprox = "approx" // 近似统计 pg_class.reltuples，仅 PostgreSQL 且无过滤条件、作用域时生效，否则精确统计
+	CountNone   = "none"   // 不统计
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrCursorSecret  = errors.New("cursor secret not configured")
+)
+
+// CursorOptions 游标分页查询选项
+type CursorOptions struct {
+	Options
+	KeyColumn    string // 唯一键列名，用于排序值相同时的次级排序，为空时使用 id
+	DefaultCount string // 默认总数统计模式，为空时使用 CountNone
+}
+
+// Cursor 游标内容，编码后签名返回给客户端
+type Cursor struct {
+	Column string      `json:"c"` // 排序列
+	Desc   bool        `json:"d"` // 是否倒序
+	Value  interface{} `json:"v"` // 排序列的值
+	Key    interface{} `json:"k"` // 唯一键的值
+	Prev   bool        `json:"p"` // 是否向前翻页
+}
+
+// CursorReq 游标分页查询请求
+type CursorReq struct {
+	Size      int
+	Order     Order
+	KeyColumn string
+	Cursor    *Cursor
+	Count     string
+	Filters   []Filter
+}
+
+// CursorMeta 游标分页结果
+type CursorMeta struct {
+	Next    string // 下一页游标，无下一页时为空
+	Prev    string // 上一页游标，无上一页时为空
+	HasMore bool   // 是否有下一页
+}
+
+// BindCursor 从请求参数绑定游标分页查询
+//
+//	page_size=10&sort=-create_time&cursor=xxx&count=approx&filter=address:eq:0x123
+//
+// 携带游标时排序以游标为准，sort 参数被忽略
+func BindCursor(c *gin.Context, opts CursorOptions) (*CursorReq, error) {
+	defaultSize, maxSize := opts.DefaultSize, opts.MaxSize
+	if defaultSize <= 0 {
+		defaultSize = DefaultSize
+	}
+	if maxSize <= 0 {
+		maxSize = MaxSize
+	}
+	keyColumn := opts.KeyColumn
+	if keyColumn == "" {
+		keyColumn = "id"
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultSize)))
+	if err != nil || size < 1 || size > maxSize {
+		return nil, fmt.Errorf("invalid page_size %q, must be between 1 and %d", c.Query("page_size"), maxSize)
+	}
+
+	count := c.DefaultQuery("count", opts.DefaultCount)
+	switch count {
+	case "":
+		count = CountNone
+	case CountExact, CountApprox, CountNone:
+	default:
+		return nil, fmt.Errorf("unsupported count mode %q", count)
+	}
+
+	filters, err := ParseFilters(c.QueryArray("filter"), opts.FilterFields)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &CursorReq{
+		Size:      size,
+		KeyColumn: keyColumn,
+		Count:     count,
+		Filters:   filters,
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		cursor, err := DecodeCursor(token)
+		if err != nil {
+			return nil, err
+		}
+		if !containsColumn(opts.SortFields, cursor.Column) && cursor.Column != keyColumn {
+			return nil, ErrInvalidCursor
+		}
+		req.Cursor = cursor
+		req.Order = Order{Column: cursor.Column, Desc: cursor.Desc}
+		return req, nil
+	}
+
+	orders, err := ParseSort(c.DefaultQuery("sort", opts.DefaultSort), opts.SortFields)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) > 1 {
+		return nil, errors.New("cursor pagination supports only one sort field")
+	}
+	req.Order = Order{Column: keyColumn, Desc: true}
+	if len(orders) == 1 {
+		req.Order = orders[0]
+	}
+	return req, nil
+}
+
+// Filter 过滤 GORM scope
+func (r *CursorReq) Filter(db *gorm.DB) *gorm.DB {
+	return (&PageReq{Filters: r.Filters}).Filter(db)
+}
+
+// backward 是否向前翻页
+func (r *CursorReq) backward() bool {
+	return r.Cursor != nil && r.Cursor.Prev
+}
+
+// seek 按游标定位并排序的 GORM scope，多取一条用于判断是否还有数据
+func (r *CursorReq) seek(db *gorm.DB) *gorm.DB {
+	// 向前翻页时反转排序方向，查询后再反转结果
+	desc := r.Order.Desc != r.backward()
+
+	if r.Cursor != nil {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		if r.Order.Column == r.KeyColumn {
+			db = db.Where(clause.Expr{
+				SQL:  fmt.Sprintf("? %s ?", op),
+				Vars: []interface{}{clause.Column{Name: r.KeyColumn}, r.Cursor.Key},
+			})
+		} else {
+			db = db.Where(clause.Expr{
+				SQL:  fmt.Sprintf("(?, ?) %s (?, ?)", op),
+				Vars: []interface{}{clause.Column{Name: r.Order.Column}, clause.Column{Name: r.KeyColumn}, r.Cursor.Value, r.Cursor.Key},
+			})
+		}
+	}
+
+	if r.Order.Column != r.KeyColumn {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: r.Order.Column}, Desc: desc})
+	}
+	return db.Order(clause.OrderByColumn{Column: clause.Column{Name: r.KeyColumn}, Desc: desc}).Limit(r.Size + 1)
+}
+
+// FindCursor 按游标查询一页数据并生成上一页/下一页游标
+func FindCursor[T any](db *gorm.DB, req *CursorReq, list *[]T) (*CursorMeta, error) {
+	tx := db.Session(&gorm.Session{}).Scopes(req.seek).Find(list)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	rows := *list
+	more := len(rows) > req.Size
+	if more {
+		rows = rows[:req.Size]
+	}
+	if req.backward() {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	*list = rows
+
+	meta := &CursorMeta{}
+	if len(rows) == 0 {
+		return meta, nil
+	}
+
+	hasNext, hasPrev := more, req.Cursor != nil
+	if req.backward() {
+		hasNext, hasPrev = true, more
+	}
+	meta.HasMore = hasNext
+
+	var err error
+	if hasNext {
+		if meta.Next, err = req.encodeRow(tx, rows[len(rows)-1], false); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if meta.Prev, err = req.encodeRow(tx, rows[0], true); err != nil {
+			return nil, err
+		}
+	}
+	return meta, nil
+}
+
+// encodeRow 根据数据行的排序列和唯一键生成游标
+func (r *CursorReq) encodeRow(tx *gorm.DB, row interface{}, prev bool) (string, error) {
+	s := tx.Statement.Schema
+	if s == nil {
+		return "", errors.New("cursor pagination requires a model schema")
+	}
+	rv := reflect.ValueOf(row)
+	valueOf := func(column string) (interface{}, error) {
+		field := s.LookUpField(column)
+		if field == nil {
+			return nil, fmt.Errorf("column %s not found in %s", column, s.Name)
+		}
+		v, _ := field.ValueOf(tx.Statement.Context, rv)
+		return v, nil
+	}
+
+	key, err := valueOf(r.KeyColumn)
+	if err != nil {
+		return "", err
+	}
+	cursor := &Cursor{Column: r.Order.Column, Desc: r.Order.Desc, Key: key, Prev: prev}
+	if r.Order.Column != r.KeyColumn {
+		if cursor.Value, err = valueOf(r.Order.Column); err != nil {
+			return "", err
+		}
+	}
+	return EncodeCursor(cursor)
+}
+
+// Total 按统计模式查询总数，CountNone 或无法估算时返回 nil
+//
+// 估算值为整表行数，有过滤条件、scoped 为 true（查询带有作用域，如逻辑删除过滤）或非 PostgreSQL 时 CountApprox 按 CountExact 统计
+func (r *CursorReq) Total(db *gorm.DB, table string, scoped bool) (*int64, error) {
+	var total int64
+	count := r.Count
+	if count == CountApprox && (scoped || len(r.Filters) > 0 || db.Dialector.Name() != "postgres") {
+		count = CountExact
+	}
+	switch count {
+	case CountExact:
+		if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+	case CountApprox:
+		// reltuples 为 -1 表示表尚未 analyze
+		err := db.Session(&gorm.Session{NewDB: true}).
+			Raw("SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(?)", table).
+			Scan(&total).Error
+		if err != nil {
+			return nil, err
+		}
+		if total < 0 {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+	return &total, nil
+}
+
+// EncodeCursor 编码并签名游标 格式为 base64(payload).base64(hmac)
+func EncodeCursor(c *Cursor) (string, error) {
+	secret, err := cursorSecret()
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// DecodeCursor 校验签名并解码游标
+func DecodeCursor(token string) (*Cursor, error) {
+	secret, err := cursorSecret()
+	if err != nil {
+		return nil, err
+	}
+	payloadPart, sigPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	// 数值以字符串形式传给数据库，避免大整数精度丢失
+	if n, ok := c.Value.(json.Number); ok {
+		c.Value = n.String()
+	}
+	if n, ok := c.Key.(json.Number); ok {
+		c.Key = n.String()
+	}
+	return &c, nil
+}
+
+func cursorSecret() ([]byte, error) {
+	if config.Conf == nil || config.Conf.App.Secret == "" {
+		return nil, ErrCursorSecret
+	}
+	return []byte(config.Conf.App.Secret), nil
+}
+
+func containsColumn(fields map[string]string, column string) bool {
+	for _, c := range fields {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package query
+
+import (
+	"bossfi-backend/src/core/config"
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setSecret(t *testing.T, secret string) {
+	t.Helper()
+	old := config.Conf
+	config.Conf = &config.Config{}
+	config.Conf.App.Secret = secret
+	t.Cleanup(func() {
+		config.Conf = old
+	})
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	setSecret(t, "secret")
+	tests := []struct {
+		name   string
+		cursor Cursor
+		want   Cursor
+	}{
+		{"string value", Cursor{Column: "address", Value: "0xabc", Key: 7},
+			Cursor{Column: "address", Value: "0xabc", Key: "7"}},
+		// 数值解码为字符串，大整数不丢失精度
+		{"big int", Cursor{Column: "id", Desc: true, Value: uint64(18446744073709551615), Key: int64(9007199254740993)},
+			Cursor{Column: "id", Desc: true, Value: "18446744073709551615", Key: "9007199254740993"}},
+		{"null value prev", Cursor{Column: "modify_time", Value: nil, Key: 1, Prev: true},
+			Cursor{Column: "modify_time", Value: nil, Key: "1", Prev: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := EncodeCursor(&tt.cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := DecodeCursor(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("DecodeCursor = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorTamper(t *testing.T) {
+	setSecret(t, "secret")
+	token, err := EncodeCursor(&Cursor{Column: "id", Value: 10, Key: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, sig, _ := strings.Cut(token, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"c":"id","d":false,"v":1,"k":1,"p":false}`))
+
+	tests := []struct {
+		name   string
+		token  string
+		secret string
+	}{
+		{"modified payload", forged + "." + sig, "secret"},
+		{"modified signature", payload + "." + base64.RawURLEncoding.EncodeToString([]byte("sig")), "secret"},
+		{"other secret", token, "other"},
+		{"missing signature", payload, "secret"},
+		{"bad payload encoding", "!!." + sig, "secret"},
+		{"bad signature encoding", payload + ".!!", "secret"},
+		{"empty", "", "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setSecret(t, tt.secret)
+			if _, err := DecodeCursor(tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestCursorSecretRequired(t *testing.T) {
+	setSecret(t, "")
+	if _, err := EncodeCursor(&Cursor{Column: "id"}); !errors.Is(err, ErrCursorSecret) {
+		t.Errorf("EncodeCursor = %v, want ErrCursorSecret", err)
+	}
+	if _, err := DecodeCursor("a.b"); !errors.Is(err, ErrCursorSecret) {
+		t.Errorf("DecodeCursor = %v, want ErrCursorSecret", err)
+	}
+}
+
+func TestBindCursorColumn(t *testing.T) {
+	setSecret(t, "secret")
+	gin.SetMode(gin.TestMode)
+	opts := CursorOptions{Options: Options{SortFields: map[string]string{"create_time": "create_time"}}}
+
+	tests := []struct {
+		column string
+		valid  bool
+	}{
+		{"create_time", true},
+		{"id", true}, // 唯一键列
+		{"password", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			token, err := EncodeCursor(&Cursor{Column: tt.column, Value: 1, Key: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/?cursor="+url.QueryEscape(token), nil)
+
+			req, err := BindCursor(c, opts)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Errorf("BindCursor = %v, want ErrInvalidCursor", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.Order.Column != tt.column || req.Cursor == nil {
+				t.Errorf("BindCursor = %+v", req)
+			}
+		})
+	}
+}
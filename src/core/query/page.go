@@ -0,0 +1,199 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultSize 默认每页条数
+	DefaultSize = 10
+	// MaxSize 默认每页最大条数
+	MaxSize = 100
+	// MaxPage 最大页码，防止偏移量溢出及深分页，更深的数据使用游标分页
+	MaxPage = 10000
+)
+
+// 过滤操作符
+const (
+	OpEq    = "eq"    // 等于 address:eq:0x123
+	OpIn    = "in"    // 包含 id:in:1,2,3
+	OpLike  = "like"  // 模糊匹配 address:like:0x12
+	OpRange = "range" // 范围(闭区间，任一端可为空) create_time:range:2025-01-01,2025-02-01
+)
+
+// Options 分页查询选项，排序及过滤字段均需在白名单中声明
+type Options struct {
+	DefaultSize  int               // 默认每页条数，为0时使用 DefaultSize
+	MaxSize      int               // 每页最大条数，为0时使用 MaxSize
+	DefaultSort  string            // 默认排序 如 "-id"
+	SortFields   map[string]string // 可排序字段 请求字段名 -> 数据库列名
+	FilterFields map[string]string // 可过滤字段 请求字段名 -> 数据库列名
+}
+
+// Order 排序条件
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+// Filter 过滤条件
+type Filter struct {
+	Column string
+	Op     string
+	Values []string
+}
+
+// PageReq 分页查询请求
+type PageReq struct {
+	Page    int
+	Size    int
+	Orders  []Order
+	Filters []Filter
+}
+
+// BindPage 从请求参数绑定分页查询
+//
+//	page=1&page_size=10&sort=-create_time,id&filter=address:eq:0x123&filter=id:range:1,100
+func BindPage(c *gin.Context, opts Options) (*PageReq, error) {
+	defaultSize, maxSize := opts.DefaultSize, opts.MaxSize
+	if defaultSize <= 0 {
+		defaultSize = DefaultSize
+	}
+	if maxSize <= 0 {
+		maxSize = MaxSize
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 || page > MaxPage {
+		return nil, fmt.Errorf("invalid page %q, must be between 1 and %d", c.Query("page"), MaxPage)
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultSize)))
+	if err != nil || size < 1 || size > maxSize {
+		return nil, fmt.Errorf("invalid page_size %q, must be between 1 and %d", c.Query("page_size"), maxSize)
+	}
+
+	orders, err := ParseSort(c.DefaultQuery("sort", opts.DefaultSort), opts.SortFields)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := ParseFilters(c.QueryArray("filter"), opts.FilterFields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PageReq{
+		Page:    page,
+		Size:    size,
+		Orders:  orders,
+		Filters: filters,
+	}, nil
+}
+
+// ParseSort 解析排序参数 字段前加 "-" 表示倒序，多个字段逗号分隔
+func ParseSort(sort string, fields map[string]string) ([]Order, error) {
+	var orders []Order
+	for _, item := range strings.Split(sort, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		desc := strings.HasPrefix(item, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(item, "-"), "+")
+		column, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported sort field %q", name)
+		}
+		orders = append(orders, Order{Column: column, Desc: desc})
+	}
+	return orders, nil
+}
+
+// ParseFilters 解析过滤参数 格式为 field:op:value
+func ParseFilters(items []string, fields map[string]string) ([]Filter, error) {
+	var filters []Filter
+	for _, item := range items {
+		parts := strings.SplitN(item, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid filter %q, expect field:op:value", item)
+		}
+		column, ok := fields[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("unsupported filter field %q", parts[0])
+		}
+
+		filter := Filter{Column: column, Op: parts[1]}
+		switch filter.Op {
+		case OpEq, OpLike:
+			filter.Values = []string{parts[2]}
+		case OpIn:
+			filter.Values = strings.Split(parts[2], ",")
+		case OpRange:
+			filter.Values = strings.SplitN(parts[2], ",", 2)
+			if len(filter.Values) != 2 || (filter.Values[0] == "" && filter.Values[1] == "") {
+				return nil, fmt.Errorf("invalid range filter %q, expect from,to", item)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported filter operator %q", filter.Op)
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// Offset 分页偏移量
+func (p *PageReq) Offset() int {
+	return (p.Page - 1) * p.Size
+}
+
+// Paginate 分页 GORM scope
+func (p *PageReq) Paginate(db *gorm.DB) *gorm.DB {
+	return db.Offset(p.Offset()).Limit(p.Size)
+}
+
+// Sort 排序 GORM scope
+func (p *PageReq) Sort(db *gorm.DB) *gorm.DB {
+	for _, o := range p.Orders {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: o.Column}, Desc: o.Desc})
+	}
+	return db
+}
+
+// Filter 过滤 GORM scope
+func (p *PageReq) Filter(db *gorm.DB) *gorm.DB {
+	for _, f := range p.Filters {
+		column := clause.Column{Name: f.Column}
+		switch f.Op {
+		case OpEq:
+			db = db.Where(clause.Eq{Column: column, Value: f.Values[0]})
+		case OpIn:
+			values := make([]interface{}, 0, len(f.Values))
+			for _, v := range f.Values {
+				values = append(values, v)
+			}
+			db = db.Where(clause.IN{Column: column, Values: values})
+		case OpLike:
+			db = db.Where(clause.Like{Column: column, Value: "%" + escapeLike(f.Values[0]) + "%"})
+		case OpRange:
+			if f.Values[0] != "" {
+				db = db.Where(clause.Gte{Column: column, Value: f.Values[0]})
+			}
+			if f.Values[1] != "" {
+				db = db.Where(clause.Lte{Column: column, Value: f.Values[1]})
+			}
+		default:
+			_ = db.AddError(errors.New("unsupported filter operator " + f.Op))
+		}
+	}
+	return db
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
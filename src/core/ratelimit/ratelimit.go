@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/gomodule/redigo/redis"
+	"time"
+)
+
+// Pool Redis连接池
+type Pool interface {
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
+// gcraScript GCRA 令牌桶限流，允许 period 内最多 limit 次请求，请求均匀消耗配额并随时间平滑恢复
+// KEYS[1] 限流key ARGV[1] limit ARGV[2] period(毫秒) ARGV[3] 为 1 时只检查不消耗配额
+// 返回 {是否允许, 剩余次数, 重试等待(毫秒), 配额完全恢复时间(毫秒)}
+var gcraScript = redis.NewScript(1, `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local emission = period / limit
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if not tat or tat < now then
+	tat = now
+end
+local newTat = tat + emission
+local allowAt = newTat - period
+if now < allowAt then
+	return {0, 0, math.ceil(allowAt - now), math.ceil(tat - now)}
+end
+
+if ARGV[3] == '1' then
+	return {1, math.floor((period - (newTat - now)) / emission) + 1, 0, math.ceil(tat - now)}
+end
+redis.call('SET', KEYS[1], tostring(newTat), 'PX', math.ceil(newTat - now))
+local remaining = math.floor((period - (newTat - now)) / emission)
+return {1, remaining, 0, math.ceil(newTat - now)}
+`)
+
+// Rule 限流规则：Period 内最多 Limit 次请求
+type Rule struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Result 限流结果
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // 被拒绝时需等待的时间
+	Reset      time.Duration // 配额完全恢复的时间
+}
+
+// Limiter 基于Redis的分布式限流器，多副本共享配额
+type Limiter struct {
+	pool      Pool
+	namespace string
+}
+
+// New 创建限流器，namespace 为key前缀，一般为应用名
+func New(pool Pool, namespace string) *Limiter {
+	return &Limiter{pool: pool, namespace: namespace}
+}
+
+// Allow 消耗一次 key 的配额
+func (l *Limiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	return l.do(ctx, key, rule, false)
+}
+
+// Check 检查 key 是否还有配额，不消耗配额；用于只对失败请求计数的场景（如认证失败）
+func (l *Limiter) Check(ctx context.Context, key string, rule Rule) (*Result, error) {
+	return l.do(ctx, key, rule, true)
+}
+
+func (l *Limiter) do(ctx context.Context, key string, rule Rule, dryRun bool) (*Result, error) {
+	dry := 0
+	if dryRun {
+		dry = 1
+	}
+	conn, err := l.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rk := "ratelimit:" + key
+	if l.namespace != "" {
+		rk = l.namespace + ":" + rk
+	}
+	values, err := redis.Int64s(gcraScript.DoContext(ctx, conn, rk, rule.Limit, rule.Period.Milliseconds(), dry))
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      rule.Limit,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
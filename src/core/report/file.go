@@ -0,0 +1,47 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// File 本地文件上报，每个事件一行 JSON，作为未接入 Sentry 时的替代
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFile 创建本地文件上报，目录不存在时自动创建
+func NewFile(path string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &File{file: f}, nil
+}
+
+// Report 追加写入事件
+func (r *File) Report(_ context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.file.Write(append(data, '\n'))
+	return err
+}
+
+// Flush 同步写入磁盘
+func (r *File) Flush(time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Sync() == nil
+}
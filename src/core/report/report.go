@@ -0,0 +1,149 @@
+package report
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// 上报驱动
+const (
+	DriverFile = "file"
+	DriverNone = "none"
+)
+
+// 事件级别
+const (
+	LevelFatal = "fatal"
+	LevelError = "error"
+)
+
+// Default 默认错误上报，InitReport 后可用，未初始化时不上报
+var Default Reporter = Nop{}
+
+// Reporter 错误上报，事件结构与 Sentry 事件格式兼容，接入 Sentry 时实现该接口转发即可
+type Reporter interface {
+	// Report 上报事件
+	Report(ctx context.Context, event *Event) error
+	// Flush 等待已上报事件发送完成，退出前调用
+	Flush(timeout time.Duration) bool
+}
+
+// Event 错误事件
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Message     string            `json:"message"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Exception   []Exception       `json:"exception,omitempty"`
+	Request     *Request          `json:"request,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Exception 异常
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace 调用栈，按 Sentry 约定最早的调用在前
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame 栈帧
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Request 请求信息，敏感字段需在上报前脱敏
+type Request struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	QueryString string            `json:"query_string,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Data        string            `json:"data,omitempty"`
+}
+
+// NewEvent 创建事件，生成事件ID
+func NewEvent(level, message string) *Event {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return &Event{
+		EventID:   hex.EncodeToString(buf),
+		Timestamp: time.Now().UTC(),
+		Level:     level,
+		Message:   message,
+		Tags:      map[string]string{},
+	}
+}
+
+// Callers 当前调用栈，skip 为跳过的调用层数（0 为 Callers 的调用方）；
+// 只使用程序计数器解析函数及行号，不读取源码文件，并去掉 runtime 内部栈帧
+func Callers(skip int) *Stacktrace {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var list []Frame
+	for {
+		f, more := frames.Next()
+		if f.Function != "" && !strings.HasPrefix(f.Function, "runtime.") {
+			module, function := splitFunction(f.Function)
+			list = append(list, Frame{
+				Function: function,
+				Module:   module,
+				Filename: shortFile(f.File),
+				AbsPath:  f.File,
+				Lineno:   f.Line,
+				InApp:    strings.HasPrefix(module, "bossfi-backend/"),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	// runtime 返回最近的调用在前，Sentry 要求最早的调用在前
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	return &Stacktrace{Frames: list}
+}
+
+// splitFunction 拆分包路径和函数名 如 bossfi-backend/src/app/api.(*DemoApi).Create
+func splitFunction(name string) (module, function string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:slash+1+dot], name[slash+1+dot+1:]
+}
+
+// shortFile 保留最后两级路径
+func shortFile(file string) string {
+	if i := strings.LastIndex(file, "/"); i >= 0 {
+		if j := strings.LastIndex(file[:i], "/"); j >= 0 {
+			return file[j+1:]
+		}
+	}
+	return file
+}
+
+// Nop 不上报
+type Nop struct{}
+
+func (Nop) Report(context.Context, *Event) error { return nil }
+
+func (Nop) Flush(time.Duration) bool { return true }
@@ -0,0 +1,137 @@
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Langs 支持的语言列表，每个业务状态码都必须提供这些语言的消息
+var Langs = []int{LANG_ZH, LANG_EN}
+
+// LangNames 语言标识与名称映射，用于导出
+var LangNames = map[int]string{
+	LANG_ZH: "zh",
+	LANG_EN: "en",
+}
+
+// Messages 多语言消息 key为语言标识
+type Messages map[int]string
+
+// Code 业务状态码定义
+type Code struct {
+	Code       int      // 业务状态码
+	Name       string   // 常量名
+	HttpStatus int      // HTTP状态码
+	Msg        Messages // 多语言消息
+}
+
+// registry 业务状态码注册表
+var registry = map[int]*Code{}
+
+// Register 注册业务状态码，同一状态码只能注册一次
+func Register(code int, name string, httpStatus int, msg Messages) {
+	if c, exists := registry[code]; exists {
+		panic(fmt.Sprintf("result code %d already registered as %s", code, c.Name))
+	}
+	registry[code] = &Code{
+		Code:       code,
+		Name:       name,
+		HttpStatus: httpStatus,
+		Msg:        msg,
+	}
+	ErrMsgMap[code] = msg
+}
+
+// Lookup 查询已注册的业务状态码
+func Lookup(code int) (*Code, bool) {
+	c, ok := registry[code]
+	return c, ok
+}
+
+// Codes 按状态码升序返回全部已注册的业务状态码
+func Codes() []*Code {
+	list := make([]*Code, 0, len(registry))
+	for _, c := range registry {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
+
+// Check 校验每个已注册的业务状态码都提供了全部支持语言的消息
+func Check() error {
+	var missing []string
+	for _, c := range Codes() {
+		for _, lang := range Langs {
+			if strings.TrimSpace(c.Msg[lang]) == "" {
+				missing = append(missing, fmt.Sprintf("%d(%s):%s", c.Code, c.Name, LangNames[lang]))
+			}
+		}
+		if http.StatusText(c.HttpStatus) == "" {
+			missing = append(missing, fmt.Sprintf("%d(%s):http_status", c.Code, c.Name))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("result codes missing messages: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// CodeDoc 业务状态码导出格式
+type CodeDoc struct {
+	Code       int               `json:"code"`        // 业务状态码
+	Name       string            `json:"name"`        // 常量名
+	HttpStatus int               `json:"http_status"` // HTTP状态码
+	Messages   map[string]string `json:"messages"`    // 多语言消息 key为语言名称
+}
+
+// ExportDocs 导出全部业务状态码
+func ExportDocs() []CodeDoc {
+	codes := Codes()
+	docs := make([]CodeDoc, 0, len(codes))
+	for _, c := range codes {
+		messages := make(map[string]string, len(Langs))
+		for _, lang := range Langs {
+			messages[LangNames[lang]] = c.Msg[lang]
+		}
+		docs = append(docs, CodeDoc{
+			Code:       c.Code,
+			Name:       c.Name,
+			HttpStatus: c.HttpStatus,
+			Messages:   messages,
+		})
+	}
+	return docs
+}
+
+// ExportJSON 以JSON格式导出业务状态码表
+func ExportJSON() ([]byte, error) {
+	return json.MarshalIndent(ExportDocs(), "", "  ")
+}
+
+// ExportMarkdown 以Markdown表格导出业务状态码表
+func ExportMarkdown() string {
+	var b strings.Builder
+	b.WriteString("| Code | Name | HTTP Status |")
+	for _, lang := range Langs {
+		b.WriteString(" " + LangNames[lang] + " |")
+	}
+	b.WriteString("\n| --- | --- | --- |")
+	for range Langs {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, c := range Codes() {
+		_, _ = fmt.Fprintf(&b, "| %d | %s | %d |", c.Code, c.Name, c.HttpStatus)
+		for _, lang := range Langs {
+			b.WriteString(" " + strings.ReplaceAll(c.Msg[lang], "|", "\\|") + " |")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
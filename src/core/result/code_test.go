@@ -0,0 +1,59 @@
+package result
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	if err := Check(); err != nil {
+		t.Fatalf("registered codes: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		httpStatus int
+		msg        Messages
+		want       string // 错误信息包含的内容，为空表示校验通过
+	}{
+		{"complete", http.StatusOK, Messages{LANG_ZH: "测试", LANG_EN: "Test"}, ""},
+		{"missing zh", http.StatusOK, Messages{LANG_EN: "Test"}, "999901(missing zh):zh"},
+		{"blank en", http.StatusOK, Messages{LANG_ZH: "测试", LANG_EN: "  "}, "999901(blank en):en"},
+		{"unknown http status", 999, Messages{LANG_ZH: "测试", LANG_EN: "Test"}, "999901(unknown http status):http_status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const code = 999901
+			Register(code, tt.name, tt.httpStatus, tt.msg)
+			t.Cleanup(func() {
+				delete(registry, code)
+				delete(ErrMsgMap, code)
+			})
+
+			err := Check()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Check() = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	if got := ErrMsgMap[DBNotExist][LANG_EN]; got != "Not exist" {
+		t.Errorf("ErrMsgMap[DBNotExist] = %q, want registry message", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate code did not panic")
+		}
+	}()
+	Register(DBNotExist, "Duplicate", http.StatusOK, Messages{LANG_ZH: "重复", LANG_EN: "Duplicate"})
+}
@@ -0,0 +1,49 @@
+package result
+
+// Page 分页响应
+type Page[T any] struct {
+	List    []T   `json:"list"`     // 数据列表
+	Page    int   `json:"page"`     // 当前页码
+	Size    int   `json:"size"`     // 每页条数
+	Total   int64 `json:"total"`    // 总条数
+	HasMore bool  `json:"has_more"` // 是否有下一页
+}
+
+// NewPage 构造分页响应
+func NewPage[T any](list []T, page, size int, total int64) *Page[T] {
+	if list == nil {
+		list = []T{}
+	}
+	return &Page[T]{
+		List:    list,
+		Page:    page,
+		Size:    size,
+		Total:   total,
+		HasMore: int64(page)*int64(size) < total,
+	}
+}
+
+// CursorPage 游标分页响应
+type CursorPage[T any] struct {
+	List    []T    `json:"list"`            // 数据列表
+	Size    int    `json:"size"`            // 每页条数
+	Next    string `json:"next,omitempty"`  // 下一页游标
+	Prev    string `json:"prev,omitempty"`  // 上一页游标
+	Total   *int64 `json:"total,omitempty"` // 总条数，按统计模式可能为近似值或不返回
+	HasMore bool   `json:"has_more"`        // 是否有下一页
+}
+
+// NewCursorPage 构造游标分页响应
+func NewCursorPage[T any](list []T, size int, next, prev string, hasMore bool, total *int64) *CursorPage[T] {
+	if list == nil {
+		list = []T{}
+	}
+	return &CursorPage[T]{
+		List:    list,
+		Size:    size,
+		Next:    next,
+		Prev:    prev,
+		Total:   total,
+		HasMore: hasMore,
+	}
+}
@@ -2,9 +2,14 @@ package result
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/trace"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -20,6 +25,24 @@ const (
 	ErrorCode = 100000
 	// InvalidParameter 参数错误状态码 1001xx
 	InvalidParameter = 100100
+	// UnsupportedMediaType 请求体类型不支持
+	UnsupportedMediaType = 100101
+	// TooManyRequests 请求过于频繁 1002xx
+	TooManyRequests = 100200
+	// Unauthorized 未认证 1003xx
+	Unauthorized = 100300
+	// Forbidden 无权限
+	Forbidden = 100301
+	// IdempotencyInProgress 相同幂等键的请求正在处理中 1004xx
+	IdempotencyInProgress = 100400
+	// IdempotencyKeyMismatch 幂等键已用于不同的请求
+	IdempotencyKeyMismatch = 100401
+	// VersionConflict 数据已被修改（乐观锁版本不一致） 1005xx
+	VersionConflict = 100500
+	// FieldNotWritable 请求包含不允许修改的字段
+	FieldNotWritable = 100501
+	// VersionRequired 整体更新未携带版本号
+	VersionRequired = 100502
 
 	// SystemError 系统级别错误状态码 2开头
 	SystemError = 200000
@@ -30,56 +53,115 @@ const (
 	DBDeleteFailed = 200103
 	DBQueryFailed  = 200104
 	DBNotExist     = 200105
-	// RedisError 数据库层面报错 2002xx
+	// RedisError Redis报错 2002xx
 	RedisError = 200200
 	// MQError 消息队列报错 2003xx
 	MQError = 200300
 	// EthereumError 以太坊客户端报错 2004xx
 	EthereumError = 200400
+	// RequestTimeout 请求处理超时 2005xx
+	RequestTimeout = 200500
 )
 
-// ErrMsgMap 业务错误
-var ErrMsgMap = map[int]map[int]string{
-	ErrorCode: {
+// ErrMsgMap 业务错误消息 key为业务状态码，由 Register 同步维护，只读
+//
+// Deprecated: 使用 Lookup 查询业务状态码
+var ErrMsgMap = map[int]map[int]string{}
+
+// 业务状态码注册表 每个状态码在此声明一次：常量名、HTTP状态码及多语言消息
+func init() {
+	Register(CodeOk, "CodeOk", http.StatusOK, Messages{
+		LANG_ZH: "成功",
+		LANG_EN: MsgOk,
+	})
+	Register(ErrorCode, "ErrorCode", http.StatusOK, Messages{
 		LANG_ZH: "服务器繁忙，请稍后重试",
 		LANG_EN: "Network error, please try again later",
-	},
-	InvalidParameter: {
+	})
+	Register(InvalidParameter, "InvalidParameter", http.StatusOK, Messages{
 		LANG_ZH: "参数错误，请检查",
 		LANG_EN: "Invalid parameters",
-	},
-	SystemError: {
+	})
+	Register(UnsupportedMediaType, "UnsupportedMediaType", http.StatusUnsupportedMediaType, Messages{
+		LANG_ZH: "不支持的请求体类型",
+		LANG_EN: "Unsupported media type",
+	})
+	Register(TooManyRequests, "TooManyRequests", http.StatusTooManyRequests, Messages{
+		LANG_ZH: "请求过于频繁，请稍后重试",
+		LANG_EN: "Too many requests, please try again later",
+	})
+	Register(Unauthorized, "Unauthorized", http.StatusUnauthorized, Messages{
+		LANG_ZH: "未认证或认证已失效",
+		LANG_EN: "Unauthorized",
+	})
+	Register(Forbidden, "Forbidden", http.StatusForbidden, Messages{
+		LANG_ZH: "无权限访问",
+		LANG_EN: "Forbidden",
+	})
+	Register(IdempotencyInProgress, "IdempotencyInProgress", http.StatusConflict, Messages{
+		LANG_ZH: "相同请求正在处理中，请稍后重试",
+		LANG_EN: "A request with the same idempotency key is in progress",
+	})
+	Register(IdempotencyKeyMismatch, "IdempotencyKeyMismatch", http.StatusUnprocessableEntity, Messages{
+		LANG_ZH: "幂等键已用于不同的请求",
+		LANG_EN: "Idempotency key reused with a different request",
+	})
+	Register(VersionConflict, "VersionConflict", http.StatusConflict, Messages{
+		LANG_ZH: "数据已被修改，请刷新后重试",
+		LANG_EN: "The record has been modified, please reload and retry",
+	})
+	Register(FieldNotWritable, "FieldNotWritable", http.StatusUnprocessableEntity, Messages{
+		LANG_ZH: "包含不允许修改的字段",
+		LANG_EN: "The request contains fields that cannot be modified",
+	})
+	Register(VersionRequired, "VersionRequired", http.StatusPreconditionRequired, Messages{
+		LANG_ZH: "缺少版本号，请先读取数据再更新",
+		LANG_EN: "Version is required, please read the record before updating",
+	})
+	Register(SystemError, "SystemError", http.StatusOK, Messages{
 		LANG_ZH: "服务器内部错误，请稍后重试",
 		LANG_EN: "Internal server error, please try again later",
-	},
-	DBError: {
+	})
+	Register(DBError, "DBError", http.StatusOK, Messages{
 		LANG_ZH: "数据库错误",
 		LANG_EN: "Database error",
-	},
-	DBCreateFailed: {
+	})
+	Register(DBCreateFailed, "DBCreateFailed", http.StatusOK, Messages{
 		LANG_ZH: "创建失败",
 		LANG_EN: "Create failed",
-	},
-	DBUpdateFailed: {
+	})
+	Register(DBUpdateFailed, "DBUpdateFailed", http.StatusOK, Messages{
 		LANG_ZH: "更新失败",
-		LANG_EN: "UpdateById failed",
-	},
-	DBQueryFailed: {
+		LANG_EN: "Update failed",
+	})
+	Register(DBDeleteFailed, "DBDeleteFailed", http.StatusOK, Messages{
+		LANG_ZH: "删除失败",
+		LANG_EN: "Delete failed",
+	})
+	Register(DBQueryFailed, "DBQueryFailed", http.StatusOK, Messages{
 		LANG_ZH: "查询失败",
 		LANG_EN: "Query failed",
-	},
-	DBDeleteFailed: {
-		LANG_ZH: "删除失败",
-		LANG_EN: "DeleteById failed",
-	},
-	DBNotExist: {
+	})
+	Register(DBNotExist, "DBNotExist", http.StatusOK, Messages{
 		LANG_ZH: "数据不存在",
 		LANG_EN: "Not exist",
-	},
-	EthereumError: {
+	})
+	Register(RedisError, "RedisError", http.StatusOK, Messages{
+		LANG_ZH: "缓存服务错误",
+		LANG_EN: "Cache service error",
+	})
+	Register(MQError, "MQError", http.StatusOK, Messages{
+		LANG_ZH: "消息队列错误",
+		LANG_EN: "Message queue error",
+	})
+	Register(EthereumError, "EthereumError", http.StatusOK, Messages{
 		LANG_ZH: "ETH客户端错误",
 		LANG_EN: "ETH client error",
-	},
+	})
+	Register(RequestTimeout, "RequestTimeout", http.StatusGatewayTimeout, Messages{
+		LANG_ZH: "请求处理超时，请稍后重试",
+		LANG_EN: "Request timeout, please try again later",
+	})
 }
 
 type Response struct {
@@ -89,7 +171,20 @@ type Response struct {
 	Data    interface{} `json:"data" extensions:"x-order=003"`             // 数据
 }
 
+// CacheControlKey gin 上下文中响应缓存策略的key，通过 CacheControl 设置
+const CacheControlKey = "cache_control"
+
+// CacheControl 标记本次 GET 响应可缓存：OK 返回时生成 ETag 并设置 Cache-Control，
+// 请求头 If-None-Match 与 ETag 一致时返回 304 且不返回响应体
+func CacheControl(c *gin.Context, value string) {
+	c.Set(CacheControlKey, value)
+}
+
 func OK(c *gin.Context, v interface{}) {
+	if cc := c.GetString(CacheControlKey); cc != "" && (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) {
+		okCacheable(c, v, cc)
+		return
+	}
 	c.JSON(http.StatusOK, &Response{
 		TraceId: GetTraceId(c.Request.Context()),
 		Code:    CodeOk,
@@ -98,9 +193,53 @@ func OK(c *gin.Context, v interface{}) {
 	})
 }
 
+// okCacheable 按响应数据生成弱 ETag（不含 trace_id），命中 If-None-Match 时返回 304
+func okCacheable(c *gin.Context, v interface{}, cacheControl string) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		c.JSON(http.StatusOK, &Response{
+			TraceId: GetTraceId(c.Request.Context()),
+			Code:    CodeOk,
+			Msg:     MsgOk,
+			Data:    v,
+		})
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cacheControl)
+	if etagMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.JSON(http.StatusOK, &Response{
+		TraceId: GetTraceId(c.Request.Context()),
+		Code:    CodeOk,
+		Msg:     MsgOk,
+		Data:    json.RawMessage(data),
+	})
+}
+
+// etagMatch If-None-Match 弱比较，支持多个 ETag 及 *
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
 func Error(c *gin.Context, errorCode int) {
+	errorCode = timeoutCode(c, errorCode)
 	msg := getErrorMsg(errorCode, GetLang(c))
-	c.JSON(http.StatusOK, &Response{
+	c.JSON(getHttpStatus(errorCode), &Response{
 		TraceId: GetTraceId(c.Request.Context()),
 		Code:    errorCode,
 		Msg:     msg,
@@ -111,9 +250,9 @@ func Error(c *gin.Context, errorCode int) {
 func SysError(c *gin.Context, message string) {
 	msg := message
 	if message == "" {
-		msg = ErrMsgMap[SystemError][GetLang(c)]
+		msg = getErrorMsg(SystemError, GetLang(c))
 	}
-	c.JSON(http.StatusOK, &Response{
+	c.JSON(getHttpStatus(SystemError), &Response{
 		TraceId: GetTraceId(c.Request.Context()),
 		Code:    SystemError,
 		Msg:     msg,
@@ -122,8 +261,9 @@ func SysError(c *gin.Context, message string) {
 }
 
 func ErrorData(c *gin.Context, errorCode int, data interface{}) {
+	errorCode = timeoutCode(c, errorCode)
 	msg := getErrorMsg(errorCode, GetLang(c))
-	c.JSON(http.StatusOK, &Response{
+	c.JSON(getHttpStatus(errorCode), &Response{
 		TraceId: GetTraceId(c.Request.Context()),
 		Code:    errorCode,
 		Msg:     msg,
@@ -131,6 +271,14 @@ func ErrorData(c *gin.Context, errorCode int, data interface{}) {
 	})
 }
 
+// timeoutCode 请求 context 已超时时返回 RequestTimeout，下游调用因超时失败时不再返回各自的错误码
+func timeoutCode(c *gin.Context, errorCode int) int {
+	if errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		return RequestTimeout
+	}
+	return errorCode
+}
+
 // GetTraceId 获取链路追踪id 预留，暂未启用
 func GetTraceId(ctx context.Context) string {
 	spanCtx := trace.SpanContextFromContext(ctx)
@@ -150,10 +298,17 @@ func GetLang(c *gin.Context) int {
 }
 
 func getErrorMsg(errorCode int, lang int) string {
-	if msgMap, ok := ErrMsgMap[errorCode]; ok {
-		if msg, exists := msgMap[lang]; exists {
+	if code, ok := registry[errorCode]; ok {
+		if msg, exists := code.Msg[lang]; exists {
 			return msg
 		}
 	}
-	return ErrMsgMap[ErrorCode][LANG_ZH]
+	return registry[ErrorCode].Msg[lang]
+}
+
+func getHttpStatus(errorCode int) int {
+	if code, ok := registry[errorCode]; ok {
+		return code.HttpStatus
+	}
+	return http.StatusOK
 }
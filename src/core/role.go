@@ -0,0 +1,52 @@
+package core
+
+import (
+	"bossfi-backend/src/core/auth"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runRole 角色绑定管理，角色在代码中通过 auth.DefineRole 声明
+// 用法: role grant|revoke -type wallet|api_key -subject s -role r | list [-type t] [-subject s]
+func runRole(configFile string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: role grant|revoke -type wallet|api_key -subject s -role r | list [-type t] [-subject s]")
+	}
+
+	initConfig(configFile)
+	initLog()
+	initDataSources()
+
+	ctx := context.Background()
+	fs := flag.NewFlagSet("role "+args[0], flag.ContinueOnError)
+	subjectType := fs.String("type", "", "subject type: wallet or api_key")
+	subject := fs.String("subject", "", "wallet address or api key id")
+	role := fs.String("role", "", "role name")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "grant", "revoke":
+		if *subjectType == "" || *subject == "" || *role == "" {
+			return fmt.Errorf("-type, -subject and -role are required")
+		}
+		if args[0] == "grant" {
+			return auth.GrantRole(ctx, *subjectType, *subject, *role)
+		}
+		return auth.RevokeRole(ctx, *subjectType, *subject, *role)
+	case "list":
+		list, err := auth.ListRoleBindings(ctx, *subjectType, *subject)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	default:
+		return fmt.Errorf("unknown role command %q", args[0])
+	}
+}
@@ -15,6 +15,310 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/auth/api_keys": {
+            "get": {
+                "description": "需要 apikey:admin 授权",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证接口"
+                ],
+                "summary": "API Key列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "所属方",
+                        "name": "owner",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/auth.ApiKey"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "需要 apikey:admin 授权，返回的 key 明文只在创建时返回一次；授权范围不能超出调用方自身的权限，否则返回 Forbidden",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证接口"
+                ],
+                "summary": "创建API Key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "API Key信息",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ApiKeyCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.ApiKeyCreated"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/api_keys/{id}": {
+            "delete": {
+                "description": "需要 apikey:admin 授权，吊销后立即失效",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证接口"
+                ],
+                "summary": "吊销API Key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "API Key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/result.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "删除 session_id 请求头对应的钱包登录会话",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证接口"
+                ],
+                "summary": "退出登录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "会话 token",
+                        "name": "session_id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/result.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/permissions": {
+            "get": {
+                "description": "返回调用方绑定的角色及有效权限（API Key 授权范围与角色权限的并集），未认证返回 Unauthorized",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证接口"
+                ],
+                "summary": "当前调用方权限",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key",
+                        "name": "X-API-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "钱包登录会话 token",
+                        "name": "session_id",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.PermissionsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/wallet/login": {
+            "post": {
+                "description": "校验登录消息的签名，成功返回会话 token（24 小时有效），后续请求通过 session_id 请求头携带；\n签名错误或登录消息已过期、已使用返回 Unauthorized",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证接口"
+                ],
+                "summary": "钱包登录",
+                "parameters": [
+                    {
+                        "description": "钱包地址及签名",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.WalletLoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.WalletLoginResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/wallet/nonce": {
+            "post": {
+                "description": "返回 nonce 及待签名的登录消息，5 分钟内有效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证接口"
+                ],
+                "summary": "获取钱包登录消息",
+                "parameters": [
+                    {
+                        "description": "钱包地址",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.WalletNonceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.WalletNonceResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
         "/demo/create": {
             "post": {
                 "description": "用于测试服务器连通性",
@@ -40,6 +344,1019 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/demo/cursor": {
+            "get": {
+                "description": "适用于大表的键集分页，使用响应中的 next/prev 作为 cursor 参数翻页；携带 cursor 时 sort 参数被忽略",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "示例接口"
+                ],
+                "summary": "游标分页查询数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页条数(1-100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段(仅支持一个) 如 -create_time",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "exact",
+                        "description": "总数统计模式 exact|approx|none，approx 有过滤条件或为逻辑删除表时按 exact 统计",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "过滤 如 address:eq:0x123",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/result.CursorPage-model_Demo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/deleted": {
+            "get": {
+                "description": "默认按删除时间倒序；filter 支持 deleted_at:range:开始,结束、deleted_by:eq:wallet:0x123",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "示例接口"
+                ],
+                "summary": "分页查询已删除数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码(1-10000)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页条数(1-100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序 如 -deleted_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "过滤 如 deleted_by:eq:system",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/result.Page-model_Demo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/page": {
+            "get": {
+                "description": "sort 为逗号分隔的排序字段，\"-\" 前缀表示倒序；filter 格式为 field:op:value，op 支持 eq/in/like/range",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "示例接口"
+                ],
+                "summary": "分页查询数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码(1-10000)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页条数(1-100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序 如 -create_time,id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "过滤 如 address:eq:0x123、id:range:1,100",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/result.Page-model_Demo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/{id}": {
+            "put": {
+                "description": "整体更新可修改的字段（address、logs），其他字段忽略；version 为读取时的版本号，必须携带，\n缺少时返回 VersionRequired(HTTP 428)，与当前版本不一致时返回 VersionConflict(HTTP 409)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "示例接口"
+                ],
+                "summary": "更新数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "可修改的字段",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DemoUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Demo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "JSON Merge Patch(RFC 7386)，只能包含 address、logs、version，包含其他字段返回 FieldNotWritable(HTTP 422)；\n值为 null 的字段清空；指定 version 时与当前版本不一致返回 VersionConflict(HTTP 409)；\nContent-Type 必须为 application/merge-patch+json，否则返回 UnsupportedMediaType(HTTP 415)",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "示例接口"
+                ],
+                "summary": "部分更新数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "merge patch，只需包含要修改的字段",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DemoUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Demo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/{id}/purge": {
+            "delete": {
+                "description": "仅限管理员，只能永久删除已删除(deleted=true)的记录，未删除的记录返回 DBNotExist",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "示例接口"
+                ],
+                "summary": "永久删除数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/result.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/{id}/restore": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "示例接口"
+                ],
+                "summary": "恢复已删除数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Demo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/audit_logs": {
+            "get": {
+                "description": "需要 audit:read 授权；按操作者、实体及时间范围过滤 如 filter=actor:eq:0x123\u0026filter=entity:eq:bossfi_demo\u0026filter=create_time:range:2025-01-01,2025-02-01",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统接口"
+                ],
+                "summary": "审计日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码(1-10000)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页条数(1-100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-id",
+                        "description": "排序 如 -id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "过滤 支持 actor_type/actor/action/entity/entity_id/route/request_id/create_time",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/result.Page-audit_Log"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/codes": {
+            "get": {
+                "description": "导出全部业务状态码及其HTTP状态码和多语言消息，format=md 时返回Markdown表格",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统接口"
+                ],
+                "summary": "业务状态码表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "导出格式 json|md",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/result.CodeDoc"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/mq/dead_letters": {
+            "get": {
+                "description": "查询 topic 最近处理失败进入死信队列的消息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统接口"
+                ],
+                "summary": "死信消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "topic",
+                        "name": "topic",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "条数",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/result.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/api.DeadLetter"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.ApiKeyCreated": {
+            "type": "object",
+            "properties": {
+                "create_time": {
+                    "type": "string"
+                },
+                "expire_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "API Key 明文",
+                    "type": "string"
+                },
+                "last_used_time": {
+                    "type": "string"
+                },
+                "modify_time": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "rate_limit": {
+                    "type": "integer"
+                },
+                "rate_period": {
+                    "type": "integer"
+                },
+                "revoke_time": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "description": "投递次数",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "最后一次处理失败的原因",
+                    "type": "string"
+                },
+                "group": {
+                    "description": "处理失败的消费组",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "原消息ID",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "消息内容",
+                    "type": "string"
+                },
+                "topic": {
+                    "description": "topic",
+                    "type": "string"
+                }
+            }
+        },
+        "api.PermissionsResp": {
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "description": "有效权限",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "roles": {
+                    "description": "绑定的角色",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes": {
+                    "description": "API Key 授权范围",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "subject": {
+                    "description": "主体标识",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "主体类型 api_key/wallet",
+                    "type": "string"
+                }
+            }
+        },
+        "api.WalletLoginReq": {
+            "type": "object",
+            "required": [
+                "address",
+                "nonce",
+                "signature"
+            ],
+            "properties": {
+                "address": {
+                    "description": "钱包地址",
+                    "type": "string"
+                },
+                "nonce": {
+                    "description": "获取登录消息时返回的 nonce",
+                    "type": "string"
+                },
+                "signature": {
+                    "description": "登录消息的签名 0x 开头的十六进制",
+                    "type": "string"
+                }
+            }
+        },
+        "api.WalletLoginResp": {
+            "type": "object",
+            "properties": {
+                "expire_time": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.WalletNonceReq": {
+            "type": "object",
+            "required": [
+                "address"
+            ],
+            "properties": {
+                "address": {
+                    "description": "钱包地址",
+                    "type": "string"
+                }
+            }
+        },
+        "api.WalletNonceResp": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.Log": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "actor_type": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "before": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "create_time": {
+                    "type": "string"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.ApiKey": {
+            "type": "object",
+            "properties": {
+                "create_time": {
+                    "type": "string"
+                },
+                "expire_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_time": {
+                    "type": "string"
+                },
+                "modify_time": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "rate_limit": {
+                    "type": "integer"
+                },
+                "rate_period": {
+                    "type": "integer"
+                },
+                "revoke_time": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth.ApiKeyCreate": {
+            "type": "object",
+            "required": [
+                "name",
+                "owner"
+            ],
+            "properties": {
+                "expire_time": {
+                    "description": "过期时间，为空永不过期",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "所属方",
+                    "type": "string"
+                },
+                "rate_limit": {
+                    "description": "限流次数，0 使用默认规则",
+                    "type": "integer"
+                },
+                "rate_period": {
+                    "description": "限流时间窗口(秒)",
+                    "type": "integer"
+                },
+                "scopes": {
+                    "description": "授权范围 如 [\"evm:read\",\"demo:write\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.Demo": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "create_time": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "deleted_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "logs": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "modify_time": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.DemoUpdate": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "logs": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "version": {
+                    "description": "客户端读取时的版本号，用于乐观锁，0 表示不校验",
+                    "type": "integer"
+                }
+            }
+        },
+        "result.CodeDoc": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "业务状态码",
+                    "type": "integer"
+                },
+                "http_status": {
+                    "description": "HTTP状态码",
+                    "type": "integer"
+                },
+                "messages": {
+                    "description": "多语言消息 key为语言名称",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "常量名",
+                    "type": "string"
+                }
+            }
+        },
+        "result.CursorPage-model_Demo": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "description": "是否有下一页",
+                    "type": "boolean"
+                },
+                "list": {
+                    "description": "数据列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Demo"
+                    }
+                },
+                "next": {
+                    "description": "下一页游标",
+                    "type": "string"
+                },
+                "prev": {
+                    "description": "上一页游标",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "每页条数",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "总条数，按统计模式可能为近似值或不返回",
+                    "type": "integer"
+                }
+            }
+        },
+        "result.Page-audit_Log": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "description": "是否有下一页",
+                    "type": "boolean"
+                },
+                "list": {
+                    "description": "数据列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audit.Log"
+                    }
+                },
+                "page": {
+                    "description": "当前页码",
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "每页条数",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "总条数",
+                    "type": "integer"
+                }
+            }
+        },
+        "result.Page-model_Demo": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "description": "是否有下一页",
+                    "type": "boolean"
+                },
+                "list": {
+                    "description": "数据列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Demo"
+                    }
+                },
+                "page": {
+                    "description": "当前页码",
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "每页条数",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "总条数",
+                    "type": "integer"
+                }
+            }
+        },
+        "result.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "状态码",
+                    "type": "integer",
+                    "x-order": "001",
+                    "example": 0
+                },
+                "msg": {
+                    "description": "消息",
+                    "type": "string",
+                    "x-order": "002",
+                    "example": "OK"
+                },
+                "data": {
+                    "description": "数据",
+                    "x-order": "003"
+                },
+                "trace_id": {
+                    "description": "链路追踪id",
+                    "type": "string",
+                    "example": "a1b2c3d4e5f6g7h8"
+                }
+            }
         }
     }
 }`
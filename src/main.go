@@ -3,6 +3,7 @@ package main
 import (
 	"bossfi-backend/src/core"
 	_ "bossfi-backend/src/docs"
+	"os"
 )
 
 const (
@@ -11,5 +12,5 @@ const (
 )
 
 func main() {
-	core.Start(ConfigFile)
+	core.Run(ConfigFile, os.Args[1:])
 }
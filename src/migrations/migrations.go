@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// 迁移脚本 schema/{version}_{name}.up.sql / .down.sql，已应用的脚本禁止修改，变更请新增版本
+//
+//go:embed schema/*.sql
+var schema embed.FS
+
+// 种子数据 seed/{env}/{version}_{name}.sql，按环境执行
+//
+//go:embed seed
+var seed embed.FS
+
+// Schema 迁移脚本目录
+func Schema() fs.FS {
+	sub, _ := fs.Sub(schema, "schema")
+	return sub
+}
+
+// Seed 种子数据目录
+func Seed() fs.FS {
+	sub, _ := fs.Sub(seed, "seed")
+	return sub
+}
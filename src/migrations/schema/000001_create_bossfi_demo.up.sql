@@ -0,0 +1,18 @@
+-- 示例表
+create table if not exists bossfi_demo
+(
+    id          bigint not null GENERATED BY DEFAULT AS IDENTITY
+        primary key,
+    address     varchar,
+    logs        jsonb,
+    deleted     boolean,
+    create_time timestamp(6),
+    modify_time timestamp(6)
+);
+comment on table bossfi_demo is 'Demo列表';
+comment on column bossfi_demo.id is 'id';
+comment on column bossfi_demo.address is 'address';
+comment on column bossfi_demo.logs is 'logs';
+comment on column bossfi_demo.deleted is '是否删除(逻辑,true-删除，false-未删除)';
+comment on column bossfi_demo.create_time is '创建时间';
+comment on column bossfi_demo.modify_time is '更新时间';
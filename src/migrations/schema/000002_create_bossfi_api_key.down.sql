@@ -0,0 +1 @@
+drop table if exists bossfi_api_key;
@@ -0,0 +1,34 @@
+-- API Key 表，仅保存 key 的 sha256 哈希
+create table if not exists bossfi_api_key
+(
+    id             bigint      not null GENERATED BY DEFAULT AS IDENTITY
+        primary key,
+    name           varchar     not null,
+    owner          varchar     not null,
+    prefix         varchar(16) not null,
+    key_hash       varchar(64) not null,
+    scopes         jsonb       not null default '[]',
+    rate_limit     integer     not null default 0,
+    rate_period    integer     not null default 0,
+    expire_time    timestamp(6),
+    last_used_time timestamp(6),
+    revoke_time    timestamp(6),
+    create_time    timestamp(6),
+    modify_time    timestamp(6)
+);
+create unique index if not exists uk_bossfi_api_key_key_hash on bossfi_api_key (key_hash);
+create index if not exists idx_bossfi_api_key_owner on bossfi_api_key (owner);
+comment on table bossfi_api_key is 'API Key';
+comment on column bossfi_api_key.id is 'id';
+comment on column bossfi_api_key.name is '名称';
+comment on column bossfi_api_key.owner is '所属方（合作方/内部任务）';
+comment on column bossfi_api_key.prefix is 'key前缀，用于识别';
+comment on column bossfi_api_key.key_hash is 'key的sha256哈希';
+comment on column bossfi_api_key.scopes is '授权范围 如 ["evm:read","demo:write"]';
+comment on column bossfi_api_key.rate_limit is '限流次数，0 表示使用默认规则';
+comment on column bossfi_api_key.rate_period is '限流时间窗口(秒)';
+comment on column bossfi_api_key.expire_time is '过期时间，为空表示永不过期';
+comment on column bossfi_api_key.last_used_time is '最后使用时间';
+comment on column bossfi_api_key.revoke_time is '吊销时间';
+comment on column bossfi_api_key.create_time is '创建时间';
+comment on column bossfi_api_key.modify_time is '更新时间';
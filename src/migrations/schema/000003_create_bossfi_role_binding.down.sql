@@ -0,0 +1 @@
+drop table if exists bossfi_role_binding;
@@ -0,0 +1,19 @@
+-- 角色绑定表，角色及其权限在代码中声明
+create table if not exists bossfi_role_binding
+(
+    id           bigint      not null GENERATED BY DEFAULT AS IDENTITY
+        primary key,
+    subject_type varchar(16) not null,
+    subject      varchar     not null,
+    role         varchar     not null,
+    create_time  timestamp(6),
+    modify_time  timestamp(6)
+);
+create unique index if not exists uk_bossfi_role_binding_subject_role on bossfi_role_binding (subject_type, subject, role);
+comment on table bossfi_role_binding is '角色绑定';
+comment on column bossfi_role_binding.id is 'id';
+comment on column bossfi_role_binding.subject_type is '主体类型 wallet-钱包地址 api_key-API Key';
+comment on column bossfi_role_binding.subject is '主体标识 钱包地址(小写)或API Key id';
+comment on column bossfi_role_binding.role is '角色';
+comment on column bossfi_role_binding.create_time is '创建时间';
+comment on column bossfi_role_binding.modify_time is '更新时间';
@@ -0,0 +1,2 @@
+drop table if exists bossfi_audit_log;
+drop function if exists bossfi_audit_log_append_only();
@@ -0,0 +1,48 @@
+-- 审计日志表，只允许追加，触发器禁止修改及删除
+create table if not exists bossfi_audit_log
+(
+    id          bigint      not null GENERATED BY DEFAULT AS IDENTITY
+        primary key,
+    actor_type  varchar(16) not null,
+    actor       varchar     not null default '',
+    method      varchar(16) not null default '',
+    route       varchar     not null default '',
+    action      varchar(16) not null,
+    entity      varchar     not null,
+    entity_id   varchar     not null,
+    before      jsonb,
+    after       jsonb,
+    ip          varchar(64) not null default '',
+    request_id  varchar(64) not null default '',
+    create_time timestamp(6)
+);
+create index if not exists idx_bossfi_audit_log_entity on bossfi_audit_log (entity, entity_id);
+create index if not exists idx_bossfi_audit_log_actor on bossfi_audit_log (actor_type, actor);
+create index if not exists idx_bossfi_audit_log_create_time on bossfi_audit_log (create_time);
+comment on table bossfi_audit_log is '审计日志';
+comment on column bossfi_audit_log.id is 'id';
+comment on column bossfi_audit_log.actor_type is '操作者类型 wallet-钱包地址 api_key-API Key anonymous-匿名 system-系统任务';
+comment on column bossfi_audit_log.actor is '操作者 钱包地址或API Key id';
+comment on column bossfi_audit_log.method is '请求方法';
+comment on column bossfi_audit_log.route is '路由';
+comment on column bossfi_audit_log.action is '操作 create/update/delete/soft_delete';
+comment on column bossfi_audit_log.entity is '实体(表名)';
+comment on column bossfi_audit_log.entity_id is '实体id';
+comment on column bossfi_audit_log.before is '变更前的字段值（更新时仅包含变更的字段）';
+comment on column bossfi_audit_log.after is '变更后的字段值（更新时仅包含变更的字段）';
+comment on column bossfi_audit_log.ip is '客户端IP';
+comment on column bossfi_audit_log.request_id is '请求ID';
+comment on column bossfi_audit_log.create_time is '创建时间';
+
+create or replace function bossfi_audit_log_append_only() returns trigger as
+$$
+begin
+    raise exception 'bossfi_audit_log is append-only';
+end;
+$$ language plpgsql;
+
+create trigger trg_bossfi_audit_log_append_only
+    before update or delete
+    on bossfi_audit_log
+    for each row
+execute function bossfi_audit_log_append_only();
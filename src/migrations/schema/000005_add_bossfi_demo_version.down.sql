@@ -0,0 +1,2 @@
+alter table bossfi_demo
+    drop column if exists version;
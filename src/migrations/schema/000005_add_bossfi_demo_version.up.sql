@@ -0,0 +1,4 @@
+-- 示例表增加乐观锁版本号
+alter table bossfi_demo
+    add column if not exists version bigint not null default 1;
+comment on column bossfi_demo.version is '版本号(乐观锁，每次更新加1)';
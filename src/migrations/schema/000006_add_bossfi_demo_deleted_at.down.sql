@@ -0,0 +1,5 @@
+drop index if exists idx_bossfi_demo_deleted_at;
+alter table bossfi_demo
+    drop column if exists deleted_at,
+    drop column if exists deleted_by;
+comment on column bossfi_audit_log.action is '操作 create/update/delete/soft_delete';
@@ -0,0 +1,13 @@
+-- 示例表记录逻辑删除时间及操作者，用于恢复及按保留期清理
+alter table bossfi_demo
+    add column if not exists deleted_at timestamp(6),
+    add column if not exists deleted_by varchar not null default '';
+-- 已删除的历史数据以更新时间作为删除时间
+update bossfi_demo
+set deleted_at = modify_time
+where deleted = true
+  and deleted_at is null;
+create index if not exists idx_bossfi_demo_deleted_at on bossfi_demo (deleted_at) where deleted = true;
+comment on column bossfi_demo.deleted_at is '逻辑删除时间';
+comment on column bossfi_demo.deleted_by is '逻辑删除操作者 如 wallet:0x123、api_key:1、system';
+comment on column bossfi_audit_log.action is '操作 create/update/delete/soft_delete/restore';